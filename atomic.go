@@ -0,0 +1,101 @@
+package goxcopy
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Merges the sources on a copy of the existing value, setting the result on it only if successful.
+func (c *Config) xMergeAtomic(ctx *Context, currentValue reflect.Value, src ...reflect.Value) error {
+	// the records of the copy are kept only if it succeeds
+	sc := c.scratchConfig()
+
+	// the first copy duplicates the existing value
+	mc := sc.mergeConfig()
+	dc := mc.Dup()
+	dc.Flags &^= XCF_OVERWRITE_EXISTING
+
+	var ret reflect.Value
+	for i, isrc := range src {
+		if err := ctx.checkDoneNow(); err != nil {
+			return err
+		}
+		ctx.sourceIndex = i
+		if !ret.IsValid() {
+			var err error
+			ret, err = dc.internalXCopyUsingExistingIfValid(ctx, isrc, reflect.TypeOf(currentValue.Interface()), currentValue)
+			if err != nil {
+				return err
+			}
+		} else {
+			// merge the rest on the copy
+			if err := mc.xCopyToExisting(ctx, isrc, ret); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the merged value is validated only after all the sources were merged
+	if err := sc.validateTree(ctx, ret); err != nil {
+		return err
+	}
+
+	if err := sc.finishCopy(ctx, currentValue.Type()); err != nil {
+		return err
+	}
+
+	return c.commitValue(ctx, sc, currentValue, ret)
+}
+
+// Returns a config that records the changes, report and provenance on copies of the ones of the config,
+// so they are only set by commitValue.
+func (c *Config) scratchConfig() *Config {
+	ret := c.Dup()
+	if c.ChangeLog != nil {
+		ret.ChangeLog = c.ChangeLog.dup()
+	}
+	if c.Report != nil {
+		ret.Report = c.Report.dup()
+	}
+	if c.Provenance != nil {
+		ret.Provenance = c.Provenance.dup()
+	}
+	return ret
+}
+
+// Sets the value on the existing destination value, and the records of the scratch config, if any,
+// on the ones of the config. The nested pointers, maps and slices of the existing value are replaced,
+// not updated in place.
+func (c *Config) commitValue(ctx *Context, scratch *Config, currentValue reflect.Value, value reflect.Value) error {
+	switch {
+	case currentValue.CanSet():
+		currentValue.Set(value)
+	case currentValue.Kind() == reflect.Ptr && !currentValue.IsNil():
+		if value.IsNil() {
+			currentValue.Elem().Set(reflect.Zero(currentValue.Elem().Type()))
+		} else {
+			currentValue.Elem().Set(value.Elem())
+		}
+	case currentValue.Kind() == reflect.Map && !currentValue.IsNil():
+		currentValue.Clear()
+		iter := value.MapRange()
+		for iter.Next() {
+			currentValue.SetMapIndex(iter.Key(), iter.Value())
+		}
+	default:
+		return newError(errors.New("Destination value is not settable, cannot set the result"), ctx)
+	}
+
+	if scratch != nil {
+		if c.ChangeLog != nil {
+			*c.ChangeLog = *scratch.ChangeLog
+		}
+		if c.Report != nil {
+			*c.Report = *scratch.Report
+		}
+		if c.Provenance != nil {
+			*c.Provenance = *scratch.Provenance
+		}
+	}
+	return nil
+}
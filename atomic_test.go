@@ -0,0 +1,153 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAtomicCopyRollback(t *testing.T) {
+	type sx struct {
+		Value1 string
+		Value2 []string
+		Value3 int
+	}
+
+	dst := &sx{
+		Value1: "d_value1",
+		Value2: []string{"one"},
+		Value3: 10,
+	}
+
+	src := map[string]interface{}{
+		"Value1": "x_value1",
+		"Value2": []string{"x_one", "x_two"},
+		"Value3": "invalid",
+	}
+
+	err := NewConfig().AddFlags(XCF_ATOMIC).CopyToExisting(src, dst)
+	if err == nil {
+		t.Fatal("Invalid value should have been an error")
+	}
+
+	if dst.Value1 != "d_value1" || !reflect.DeepEqual(dst.Value2, []string{"one"}) || dst.Value3 != 10 {
+		t.Fatalf("Destination should not have been changed: %+v", dst)
+	}
+
+	src["Value3"] = 20
+
+	err = NewConfig().AddFlags(XCF_ATOMIC).CopyToExisting(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Value1 != "x_value1" || !reflect.DeepEqual(dst.Value2, []string{"x_one", "x_two"}) || dst.Value3 != 20 {
+		t.Fatalf("Destination should have been changed: %+v", dst)
+	}
+}
+
+func TestAtomicMergeRollback(t *testing.T) {
+	dst := map[string]int{
+		"value1": 1,
+	}
+
+	err := NewConfig().AddFlags(XCF_ATOMIC).MergeToExisting(dst,
+		map[string]string{"value2": "2"},
+		map[string]string{"value3": "invalid"})
+	if err == nil {
+		t.Fatal("Invalid value should have been an error")
+	}
+
+	if !reflect.DeepEqual(dst, map[string]int{"value1": 1}) {
+		t.Fatalf("Destination should not have been changed: %v", dst)
+	}
+
+	err = NewConfig().AddFlags(XCF_ATOMIC).MergeToExisting(dst,
+		map[string]string{"value2": "2"},
+		map[string]string{"value3": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(dst, map[string]int{"value1": 1, "value2": 2, "value3": 3}) {
+		t.Fatalf("Destination should have been changed: %v", dst)
+	}
+}
+
+func TestAtomicRollbackRecords(t *testing.T) {
+	type sx struct {
+		A string
+		B int
+	}
+
+	dst := &sx{A: "a", B: 1}
+	changelog := NewChangeLog()
+	provenance := NewProvenance()
+	report := NewReport()
+
+	// the second source fails after the first one changed A
+	err := NewConfig().AddFlags(XCF_ATOMIC).
+		SetChangeLog(changelog).
+		SetProvenance(provenance).
+		SetReport(report).
+		MergeToExisting(dst,
+			map[string]interface{}{"A": "x"},
+			map[string]interface{}{"B": "invalid"})
+	if err == nil {
+		t.Fatal("Invalid value should have been an error")
+	}
+
+	if len(changelog.Changes) != 0 {
+		t.Fatalf("Changes of the failed copy should not have been recorded: %+v", changelog.Changes)
+	}
+	if len(provenance.Sources) != 0 {
+		t.Fatalf("Provenance of the failed copy should not have been recorded: %+v", provenance.Sources)
+	}
+	if len(report.Used) != 0 {
+		t.Fatalf("Report of the failed copy should not have been recorded: %+v", report.Used)
+	}
+
+	err = NewConfig().AddFlags(XCF_ATOMIC).
+		SetChangeLog(changelog).
+		SetProvenance(provenance).
+		SetReport(report).
+		MergeToExisting(dst,
+			map[string]interface{}{"A": "x"},
+			map[string]interface{}{"B": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(changelog.ChangesOn("A"), []Change{{Path: "A", Op: CHANGEOP_SET, OldValue: "a", NewValue: "x"}}) {
+		t.Fatalf("Unexpected changes: %+v", changelog.Changes)
+	}
+	if index, _ := provenance.Source("B"); index != 1 {
+		t.Fatalf("Unexpected provenance: %+v", provenance.Sources)
+	}
+	if !reflect.DeepEqual(report.Used, []string{"A", "B"}) {
+		t.Fatalf("Unexpected report: %+v", report.Used)
+	}
+}
+
+func TestAtomicReplacesNested(t *testing.T) {
+	type s2 struct {
+		V string
+	}
+	type sx struct {
+		P *s2
+	}
+
+	dst := &sx{P: &s2{V: "a"}}
+	p := dst.P
+
+	err := NewConfig().AddFlags(XCF_ATOMIC).CopyToExisting(map[string]interface{}{
+		"P": map[string]interface{}{"V": "x"},
+	}, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the nested pointer is replaced by the one of the result
+	if dst.P.V != "x" || p.V != "a" || dst.P == p {
+		t.Fatalf("Nested pointer should have been replaced: %+v %+v", dst.P, p)
+	}
+}
@@ -0,0 +1,103 @@
+package goxcopy
+
+import (
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Operation of a change on an existing value
+type ChangeOp int
+
+const (
+	// An existing value was set to a different value
+	CHANGEOP_SET ChangeOp = iota
+	// A key was added to an existing map
+	CHANGEOP_ADDKEY
+	// An element was appended to an existing slice
+	CHANGEOP_APPEND
+	// A key was deleted from an existing map
+	CHANGEOP_DELETEKEY
+	// An element was removed from the end of an existing slice
+	CHANGEOP_REMOVE
+)
+
+func (o ChangeOp) String() string {
+	switch o {
+	case CHANGEOP_SET:
+		return "set"
+	case CHANGEOP_ADDKEY:
+		return "add key"
+	case CHANGEOP_APPEND:
+		return "append"
+	case CHANGEOP_DELETEKEY:
+		return "delete key"
+	case CHANGEOP_REMOVE:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// A change made to an existing value
+type Change struct {
+	// Destination path, in the format returned by Path.String
+	Path string
+	Op   ChangeOp
+	// The value before the change, nil for added keys and appended elements
+	OldValue interface{}
+	// The value after the change, nil for deleted keys and removed elements
+	NewValue interface{}
+}
+
+// Records the changes made to existing values, like on CopyToExisting and merges.
+// Values created by the copy are not recorded, only the container they were added to.
+type ChangeLog struct {
+	Changes []Change
+}
+
+// Creates a new empty ChangeLog
+func NewChangeLog() *ChangeLog {
+	return &ChangeLog{}
+}
+
+// Returns the changes on the path or on any of its children
+func (l *ChangeLog) ChangesOn(path string) []Change {
+	var ret []Change
+	for _, ch := range l.Changes {
+		if path == "" || ch.Path == path || (len(ch.Path) > len(path) && ch.Path[:len(path)] == path && ch.Path[len(path)] == '.') {
+			ret = append(ret, ch)
+		}
+	}
+	return ret
+}
+
+func (l *ChangeLog) dup() *ChangeLog {
+	return &ChangeLog{Changes: append([]Change(nil), l.Changes...)}
+}
+
+func (l *ChangeLog) add(ctx *Context, op ChangeOp, oldValue interface{}, newValue interface{}) {
+	l.Changes = append(l.Changes, Change{
+		Path:     ctx.pathString(),
+		Op:       op,
+		OldValue: oldValue,
+		NewValue: newValue,
+	})
+}
+
+func (c *Config) recordChange(ctx *Context, op ChangeOp, oldValue interface{}, newValue reflect.Value) {
+	if c.ChangeLog != nil {
+		c.ChangeLog.add(ctx, op, oldValue, changeValue(newValue))
+	}
+}
+
+// Returns the value to record, dereferencing pointers as the pointed value may change later.
+func changeValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	v = rprim.UnderliningValue(v)
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	return valueInterface(v)
+}
@@ -0,0 +1,75 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangeLog(t *testing.T) {
+	type sx struct {
+		Value1 string
+		Value2 *int
+		Items  []string
+		Extra  map[string]interface{}
+	}
+
+	value2 := 10
+	dst := &sx{
+		Value1: "d_value1",
+		Value2: &value2,
+		Items:  []string{"one"},
+		Extra: map[string]interface{}{
+			"a": 1,
+		},
+	}
+
+	src := map[string]interface{}{
+		"Value1": "x_value1",
+		"Value2": 10,
+		"Items":  []string{"one", "two"},
+		"Extra": map[string]interface{}{
+			"a": 2,
+			"b": 3,
+		},
+	}
+
+	changelog := NewChangeLog()
+
+	err := NewConfig().SetChangeLog(changelog).CopyToExisting(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := map[string]Change{}
+	for _, ch := range changelog.Changes {
+		changes[ch.Path] = ch
+	}
+
+	expected := map[string]Change{
+		"Value1":  {Path: "Value1", Op: CHANGEOP_SET, OldValue: "d_value1", NewValue: "x_value1"},
+		"Items.1": {Path: "Items.1", Op: CHANGEOP_APPEND, NewValue: "two"},
+		"Extra.a": {Path: "Extra.a", Op: CHANGEOP_SET, OldValue: 1, NewValue: 2},
+		"Extra.b": {Path: "Extra.b", Op: CHANGEOP_ADDKEY, NewValue: 3},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Unexpected changes: %+v", changelog.Changes)
+	}
+
+	if len(changelog.ChangesOn("Extra")) != 2 || len(changelog.ChangesOn("Ext")) != 0 {
+		t.Fatal("Unexpected changes on path")
+	}
+}
+
+func TestChangeLogNew(t *testing.T) {
+	changelog := NewChangeLog()
+
+	_, err := NewConfig().SetChangeLog(changelog).CopyToNew(map[string]int{"a": 1}, reflect.TypeOf(map[string]int{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changelog.Changes) != 0 {
+		t.Fatalf("New values should not record changes: %+v", changelog.Changes)
+	}
+}
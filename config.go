@@ -19,6 +19,37 @@ const (
 	// Disable special handling of map[XXX]interface{} target, which can create a new inner map of the same type
 	// if the source have fields.
 	XCF_DISABLE_MAPOFINTERFACE_TARGET_RECURSION = 16
+	// Disable the recovery of panics on the copy functions, letting them propagate to the caller.
+	// Useful for debugging.
+	XCF_DISABLE_PANIC_RECOVERY = 32
+	// Return error if a required destination struct field was not set by any source.
+	// Fields are required if tagged with the "required" option, or if added to Config.RequiredFields.
+	XCF_ERROR_IF_REQUIRED_FIELD_MISSING = 64
+
+	// Strict mode, both source fields missing on the destination and required fields not set are errors
+	XCF_STRICT = XCF_ERROR_IF_STRUCT_FIELD_MISSING | XCF_ERROR_IF_REQUIRED_FIELD_MISSING
+
+	// Disable calling the Validator interfaces and the config validators after values are created
+	XCF_DISABLE_VALIDATION = 128
+	// Disable calling the BeforeCopyFrom, AfterCopyTo and ValueExporter interfaces
+	XCF_DISABLE_LIFECYCLE_HOOKS = 256
+	// When copying a map to a struct, iterate the struct fields and lookup the values on the map,
+	// instead of iterating all the map keys. Source keys are not reported as unused in this mode.
+	XCF_TARGET_DRIVEN = 512
+	// When copying or merging to an existing value, build the result on a copy of the value, and only
+	// set it on the existing value if there are no errors. The result replaces the existing value, so the
+	// pointers, maps and slices nested in it are new instances instead of being updated in place.
+	XCF_ATOMIC = 1024
+	// JSON Merge Patch (RFC 7396) semantics when copying to existing values: a nil source value deletes
+	// the map key or sets the struct field to its zero value, and slices are replaced instead of merged.
+	XCF_MERGE_PATCH = 2048
+	// When copying to existing values, make the existing slices and maps exactly match the source:
+	// slices are truncated to the source length, the remaining array elements are set to the zero value,
+	// and map keys not present on the source are deleted. Existing elements are still reused.
+	XCF_MIRROR = 4096
+	// Only allocate nil destination pointers to structs, maps and slices if a non-zero value is set on them,
+	// keeping them nil otherwise.
+	XCF_LAZY_POINTERS = 8192
 )
 
 //
@@ -31,9 +62,47 @@ type Config struct {
 	StructTagName string
 	// Field map
 	FieldMap map[string]*FieldMap
+	// Destination paths that are required, in addition to the ones tagged as "required"
+	RequiredFields map[string]bool
+	// Validators to call after the value of the destination path is created, or for merges, after all the sources were merged
+	Validators map[string]ValidatorFunc
+	// Converters of primitive values to the destination type, used instead of RprimConfig
+	Converters map[reflect.Type]ConverterFunc
+	// Custom creators, checked in reverse order before the default ones
+	Creators []*CreatorRegistration
+	// Custom sources, checked in reverse order before the default ones
+	Sources []*SourceRegistration
+	// Limits for copying untrusted input
+	Limits *Limits
+	// Policy of which source values are set on the destination
+	MergePolicy MergePolicy
+	// Merge policies of destination paths and their children, overriding MergePolicy
+	MergePolicies map[string]MergePolicy
+	// Strategies to merge the slices and maps of destination paths, overriding the "merge" struct tag option
+	MergeStrategies map[string]MergeStrategy
+	// Policy of how nil source values are copied
+	NilPolicy NilPolicy
+	// Nil policies of destination paths and their children, overriding NilPolicy
+	NilPolicies map[string]NilPolicy
+	// Format of the keys of Flatten and Unflatten, the default format is used if nil
+	FlattenFormat *FlattenFormat
+	// Resolves the conflicts of three-way merges. If nil, conflicts keep "ours" value.
+	ConflictResolver ConflictResolver
+	// User values set on the Context at the start of each copy, if not already set.
+	// They are available to the Converters, but not to the converters of RprimConfig, which don't receive the context.
+	ContextValues map[interface{}]interface{}
 	// Configuration of the primitive type converter
 	RprimConfig *rprim.Config
 	Callback    Callback
+	// If not nil, the report is filled with the fields used and not used in the copy
+	Report *Report
+	// If not nil, records the changes made to existing values
+	ChangeLog *ChangeLog
+	// If not nil, records which source of a merge last set each destination path
+	Provenance *Provenance
+
+	// The Report set by the user, while Report is a per-operation one used to check the required fields
+	userReport *Report
 }
 
 // Creates a new default Config
@@ -47,10 +116,19 @@ func NewConfig() *Config {
 // Duplicates the Config
 func (c *Config) Dup() *Config {
 	ret := &Config{
-		Flags:         c.Flags,
-		StructTagName: c.StructTagName,
-		RprimConfig:   c.RprimConfig.Dup(),
-		Callback:      c.Callback,
+		Flags:            c.Flags,
+		StructTagName:    c.StructTagName,
+		RprimConfig:      c.RprimConfig.Dup(),
+		Callback:         c.Callback,
+		Report:           c.Report,
+		userReport:       c.userReport,
+		ChangeLog:        c.ChangeLog,
+		Limits:           c.Limits,
+		FlattenFormat:    c.FlattenFormat,
+		MergePolicy:      c.MergePolicy,
+		NilPolicy:        c.NilPolicy,
+		ConflictResolver: c.ConflictResolver,
+		Provenance:       c.Provenance,
 	}
 	if c.FieldMap != nil {
 		ret.FieldMap = make(map[string]*FieldMap)
@@ -58,6 +136,54 @@ func (c *Config) Dup() *Config {
 			ret.FieldMap[fn] = fv
 		}
 	}
+	if c.RequiredFields != nil {
+		ret.RequiredFields = make(map[string]bool)
+		for fn, fv := range c.RequiredFields {
+			ret.RequiredFields[fn] = fv
+		}
+	}
+	if c.MergePolicies != nil {
+		ret.MergePolicies = make(map[string]MergePolicy)
+		for fn, fv := range c.MergePolicies {
+			ret.MergePolicies[fn] = fv
+		}
+	}
+	if c.NilPolicies != nil {
+		ret.NilPolicies = make(map[string]NilPolicy)
+		for fn, fv := range c.NilPolicies {
+			ret.NilPolicies[fn] = fv
+		}
+	}
+	if c.MergeStrategies != nil {
+		ret.MergeStrategies = make(map[string]MergeStrategy)
+		for fn, fv := range c.MergeStrategies {
+			ret.MergeStrategies[fn] = fv
+		}
+	}
+	if c.ContextValues != nil {
+		ret.ContextValues = make(map[interface{}]interface{})
+		for k, v := range c.ContextValues {
+			ret.ContextValues[k] = v
+		}
+	}
+	if c.Creators != nil {
+		ret.Creators = append([]*CreatorRegistration{}, c.Creators...)
+	}
+	if c.Sources != nil {
+		ret.Sources = append([]*SourceRegistration{}, c.Sources...)
+	}
+	if c.Validators != nil {
+		ret.Validators = make(map[string]ValidatorFunc)
+		for fn, fv := range c.Validators {
+			ret.Validators[fn] = fv
+		}
+	}
+	if c.Converters != nil {
+		ret.Converters = make(map[reflect.Type]ConverterFunc)
+		for ft, fv := range c.Converters {
+			ret.Converters[ft] = fv
+		}
+	}
 	return ret
 }
 
@@ -94,14 +220,159 @@ func (c *Config) GetFieldMap(fieldname string) *FieldMap {
 	return nil
 }
 
+// Add destination paths that must be set by the copy
+func (c *Config) AddRequiredFields(path ...string) *Config {
+	if c.RequiredFields == nil {
+		c.RequiredFields = make(map[string]bool)
+	}
+	for _, p := range path {
+		c.RequiredFields[p] = true
+	}
+	return c
+}
+
+// Add a validator for the destination path
+func (c *Config) AddValidator(path string, validator ValidatorFunc) *Config {
+	if c.Validators == nil {
+		c.Validators = make(map[string]ValidatorFunc)
+	}
+	c.Validators[path] = validator
+	return c
+}
+
+// Add a converter of primitive values to the destination type
+func (c *Config) AddConverter(t reflect.Type, converter ConverterFunc) *Config {
+	if c.Converters == nil {
+		c.Converters = make(map[reflect.Type]ConverterFunc)
+	}
+	c.Converters[t] = converter
+	return c
+}
+
 // Set the callback
 func (c *Config) SetCallback(callback Callback) *Config {
 	c.Callback = callback
 	return c
 }
 
+// Set the limits for copying untrusted input
+func (c *Config) SetLimits(limits *Limits) *Config {
+	c.Limits = limits
+	return c
+}
+
+// Set the merge policy
+func (c *Config) SetMergePolicy(policy MergePolicy) *Config {
+	c.MergePolicy = policy
+	return c
+}
+
+// Set the merge policy of the destination path and its children
+func (c *Config) SetPathMergePolicy(path string, policy MergePolicy) *Config {
+	if c.MergePolicies == nil {
+		c.MergePolicies = make(map[string]MergePolicy)
+	}
+	c.MergePolicies[path] = policy
+	return c
+}
+
+// Set the strategy to merge the slice or map of the destination path
+func (c *Config) SetPathMergeStrategy(path string, strategy MergeStrategy) *Config {
+	if c.MergeStrategies == nil {
+		c.MergeStrategies = make(map[string]MergeStrategy)
+	}
+	c.MergeStrategies[path] = strategy
+	return c
+}
+
+// Set the nil policy
+func (c *Config) SetNilPolicy(policy NilPolicy) *Config {
+	c.NilPolicy = policy
+	return c
+}
+
+// Set the nil policy of the destination path and its children
+func (c *Config) SetPathNilPolicy(path string, policy NilPolicy) *Config {
+	if c.NilPolicies == nil {
+		c.NilPolicies = make(map[string]NilPolicy)
+	}
+	c.NilPolicies[path] = policy
+	return c
+}
+
+// Set the resolver of three-way merge conflicts
+func (c *Config) SetConflictResolver(resolver ConflictResolver) *Config {
+	c.ConflictResolver = resolver
+	return c
+}
+
+// Set the format of the keys of Flatten and Unflatten
+func (c *Config) SetFlattenFormat(format *FlattenFormat) *Config {
+	c.FlattenFormat = format
+	return c
+}
+
+// Set a user value to be set on the Context at the start of each copy
+func (c *Config) SetContextValue(key interface{}, value interface{}) *Config {
+	if c.ContextValues == nil {
+		c.ContextValues = make(map[interface{}]interface{})
+	}
+	c.ContextValues[key] = value
+	return c
+}
+
+// Set the change log to record the changes made to existing values
+func (c *Config) SetChangeLog(changeLog *ChangeLog) *Config {
+	c.ChangeLog = changeLog
+	return c
+}
+
+// Set the provenance to be filled by merges
+func (c *Config) SetProvenance(provenance *Provenance) *Config {
+	c.Provenance = provenance
+	return c
+}
+
+// Set the report to be filled by the copy
+func (c *Config) SetReport(report *Report) *Config {
+	c.Report = report
+	return c
+}
+
 // The underling function that does the other functions work.
 func (c *Config) internalXCopyUsingExistingIfValid(ctx *Context, src reflect.Value, destType reflect.Type, currentValue reflect.Value) (reflect.Value, error) {
+	// the source may present itself as a different value
+	copySrc := c.exportValue(src)
+
+	if isNilValue(copySrc) {
+		return c.copyNil(ctx, copySrc, destType, currentValue)
+	}
+
+	if err := c.callBeforeCopyFrom(ctx, src, currentValue); err != nil {
+		return reflect.Value{}, err
+	}
+
+	var ret reflect.Value
+	source, err := c.GetSource(ctx, copySrc)
+	if err == nil {
+		if source != nil {
+			ret, err = c.copyTo_Source(ctx, source, destType, currentValue)
+		} else {
+			ret, err = c.copyToKind(ctx, copySrc, destType, currentValue)
+		}
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if err := c.callAfterCopyTo(ctx, src, ret); err != nil {
+		return reflect.Value{}, err
+	}
+	return ret, nil
+}
+
+// Copy the source using the function for its kind
+func (c *Config) copyToKind(ctx *Context, src reflect.Value, destType reflect.Type, currentValue reflect.Value) (reflect.Value, error) {
 	skind := rprim.UnderliningValueKind(src)
 
 	switch skind {
@@ -146,17 +417,8 @@ func (c *Config) copyTo_Struct(ctx *Context, src reflect.Value, destType reflect
 					continue
 				}
 
-				targetFieldName := srcFieldType.Name
-
 				// check for the struct tag and change the field name if requested
-				tag_fields := c.GetStructTagFields(srcFieldType)
-				if len(tag_fields) > 0 {
-					if tag_fields[0] == "-" {
-						targetFieldName = "" // skip
-					} else {
-						targetFieldName = tag_fields[0]
-					}
-				}
+				targetFieldName := c.GetStructFieldName(srcFieldType)
 
 				if targetFieldName != "" {
 					// check the field map for this field
@@ -165,6 +427,9 @@ func (c *Config) copyTo_Struct(ctx *Context, src reflect.Value, destType reflect
 							targetFieldName = *fieldmap.Fieldname
 						}
 					}
+
+					c.reportRenamed(ctx.pathStringAppending(reflect.ValueOf(srcFieldType.Name)),
+						ctx.pathStringAppending(reflect.ValueOf(targetFieldName)))
 				}
 
 				if targetFieldName != "" {
@@ -187,7 +452,7 @@ func (c *Config) copyTo_Struct(ctx *Context, src reflect.Value, destType reflect
 		}
 	}
 
-	return destCreator.Create()
+	return c.createValue(ctx, destCreator)
 }
 
 //
@@ -206,6 +471,16 @@ func (c *Config) copyTo_Map(ctx *Context, src reflect.Value, destType reflect.Ty
 		}
 	}
 
+	if structCreator, isstruct := destCreator.(*copyCreator_Struct); isstruct && (c.Flags&XCF_TARGET_DRIVEN) == XCF_TARGET_DRIVEN {
+		// the destination fields drive the copy
+		if srcValue.Kind() != reflect.Ptr || !srcValue.IsNil() {
+			if err := c.pullFromMap(ctx, src, structCreator); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		return c.createValue(ctx, destCreator)
+	}
+
 	if !destCreator.TryFastCopy(src) {
 		if srcValue.Kind() != reflect.Ptr || !srcValue.IsNil() {
 			for _, k := range srcValue.MapKeys() {
@@ -216,6 +491,7 @@ func (c *Config) copyTo_Map(ctx *Context, src reflect.Value, destType reflect.Ty
 				if fieldmap := c.GetFieldMap(ctx.FieldsAsStringAppending(kindex)); fieldmap != nil {
 					if fieldmap.Fieldname != nil {
 						kindex = reflect.ValueOf(*fieldmap.Fieldname)
+						c.reportRenamed(ctx.pathStringAppending(k), ctx.pathStringAppending(kindex))
 					}
 				}
 
@@ -235,7 +511,16 @@ func (c *Config) copyTo_Map(ctx *Context, src reflect.Value, destType reflect.Ty
 		}
 	}
 
-	return destCreator.Create()
+	if (srcValue.Kind() != reflect.Ptr || !srcValue.IsNil()) && srcValue.Len() == 0 {
+		// keep empty collections empty instead of nil
+		if ec, isempty := destCreator.(emptyCreator); isempty {
+			if err := ec.ensureEmpty(); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+	}
+
+	return c.createValue(ctx, destCreator)
 }
 
 //
@@ -266,6 +551,7 @@ func (c *Config) copyTo_Slice(ctx *Context, src reflect.Value, destType reflect.
 				if fieldmap := c.GetFieldMap(ctx.FieldsAsStringAppending(fv)); fieldmap != nil {
 					if fieldmap.Fieldname != nil {
 						fvindex = reflect.ValueOf(*fieldmap.Fieldname)
+						c.reportRenamed(ctx.pathStringAppending(fv), ctx.pathStringAppending(fvindex))
 					}
 				}
 
@@ -285,7 +571,16 @@ func (c *Config) copyTo_Slice(ctx *Context, src reflect.Value, destType reflect.
 		}
 	}
 
-	return destCreator.Create()
+	if (srcValue.Kind() != reflect.Ptr || !srcValue.IsNil()) && srcValue.Len() == 0 {
+		// keep empty collections empty instead of nil
+		if ec, isempty := destCreator.(emptyCreator); isempty {
+			if err := ec.ensureEmpty(); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+	}
+
+	return c.createValue(ctx, destCreator)
 }
 
 //
@@ -314,7 +609,7 @@ func (c *Config) copyTo_Primitive(ctx *Context, src reflect.Value, destType refl
 
 	c.callbackAfterSetValue(ctx, src, destCreator, currentValue) // callback
 
-	return destCreator.Create()
+	return c.createValue(ctx, destCreator)
 }
 
 // Callback helpers
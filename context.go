@@ -1,26 +1,108 @@
 package goxcopy
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"strings"
 )
 
+// Number of fields set between checks of the context.Context
+const contextCheckInterval = 64
+
 type Context struct {
 	Fields []reflect.Value
+
+	// context.Context of the copy operation, may be nil
+	goctx context.Context
+	// user values
+	values map[interface{}]interface{}
+	// number of elements set in the current copy operation
+	elements int
+	// number of fields set since the start of the copy operation, to check the context.Context periodically
+	steps int
+	// merge strategies set by struct tags, by destination path
+	mergeStrategies map[string]MergeStrategy
+	// index of the source being merged
+	sourceIndex int
+	// typed paths of the normalized values, by their string path, recorded by Diff
+	diffPaths map[string]Path
 }
 
 func NewContext() *Context {
 	return &Context{}
 }
 
+// Creates a new context that aborts the copy when the context.Context is done.
+func NewContextWithContext(goctx context.Context) *Context {
+	return &Context{
+		goctx: goctx,
+	}
+}
+
 func (c *Context) Dup() *Context {
-	ret := &Context{}
+	ret := &Context{
+		goctx: c.goctx,
+	}
 	for _, f := range c.Fields {
 		ret.Fields = append(ret.Fields, f)
 	}
+	for k, v := range c.values {
+		ret.SetValue(k, v)
+	}
 	return ret
 }
 
+// Sets a user value on the context, available to creators, sources, validators and callbacks.
+// Converters added with Config.AddConverter receive the context, but the ones of RprimConfig don't.
+// The key must be comparable, and should be of an unexported type or a ContextKey to avoid collisions.
+func (c *Context) SetValue(key interface{}, value interface{}) *Context {
+	if c.values == nil {
+		c.values = make(map[interface{}]interface{})
+	}
+	c.values[key] = value
+	return c
+}
+
+// Returns the user value for the key, or nil if not set.
+func (c *Context) Value(key interface{}) interface{} {
+	return c.values[key]
+}
+
+// Returns the user value for the key, and whether it was set.
+func (c *Context) LookupValue(key interface{}) (interface{}, bool) {
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// Returns the context.Context of the copy operation, or context.Background if none was set.
+func (c *Context) Context() context.Context {
+	if c.goctx == nil {
+		return context.Background()
+	}
+	return c.goctx
+}
+
+// Returns an error if the context.Context is done. Only checked every few calls, except the first.
+func (c *Context) checkDone() error {
+	c.steps++
+	if c.steps%contextCheckInterval != 1 {
+		return nil
+	}
+	return c.checkDoneNow()
+}
+
+// Returns an error if the context.Context is done.
+func (c *Context) checkDoneNow() error {
+	if c.goctx == nil || c.goctx.Done() == nil {
+		return nil
+	}
+	if err := c.goctx.Err(); err != nil {
+		return newError(fmt.Errorf("Copy aborted: %w", err), c)
+	}
+	return nil
+}
+
 func (c *Context) PushField(fieldname reflect.Value) {
 	c.Fields = append(c.Fields, fieldname)
 }
@@ -0,0 +1,134 @@
+package goxcopy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var localeKey = NewContextKey[string]("locale")
+
+func TestContextValues(t *testing.T) {
+	type sx struct {
+		Value1 string
+	}
+
+	var seen []string
+	validator := func(ctx *Context, value reflect.Value) error {
+		locale, ok := localeKey.Get(ctx)
+		if !ok {
+			return errors.New("Locale not set")
+		}
+		seen = append(seen, locale)
+		return nil
+	}
+
+	_, err := NewConfig().SetContextValue(localeKey, "pt_BR").AddValidator("Value1", validator).
+		CopyToNew(map[string]string{"Value1": "x_value1"}, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext()
+	localeKey.Set(ctx, "en_US")
+
+	_, err = NewConfig().SetContextValue(localeKey, "pt_BR").AddValidator("Value1", validator).
+		XCopyToNew(ctx, reflect.ValueOf(map[string]string{"Value1": "x_value1"}), reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(seen, []string{"pt_BR", "en_US"}) {
+		t.Fatalf("Unexpected context values: %v", seen)
+	}
+}
+
+func TestContextValuesDup(t *testing.T) {
+	ctx := NewContext().SetValue("tenant", 15)
+	dup := ctx.Dup()
+	dup.SetValue("tenant", 16)
+
+	if ctx.Value("tenant") != 15 || dup.Value("tenant") != 16 {
+		t.Fatal("Duplicated context should inherit the values without changing the original")
+	}
+
+	if _, ok := localeKey.Get(dup); ok {
+		t.Fatal("Value should not be set")
+	}
+}
+
+func TestContextFieldsOrder(t *testing.T) {
+	type s2 struct {
+		Value1 string
+		Value2 int
+	}
+	type s1 struct {
+		Inner s2
+	}
+
+	src := map[string]interface{}{
+		"Inner": map[string]interface{}{
+			"XValue1": "x_value1",
+		},
+	}
+
+	// field map keys and error paths go from the innermost field to the outermost one
+	var validated []string
+	ret := &s1{}
+	err := NewConfig().SetFieldMap(map[string]*FieldMap{
+		"XValue1.Inner": NewFieldMap().SetFieldname("Value1"),
+	}).AddValidator("Inner.Value1", func(ctx *Context, value reflect.Value) error {
+		// config paths go from the outermost field to the innermost one
+		validated = append(validated, ctx.FieldsAsString(), ctx.Path().String())
+		return nil
+	}).CopyToExisting(src, ret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret.Inner.Value1 != "x_value1" {
+		t.Fatalf("Nested field map was not applied: %+v", ret)
+	}
+	if !reflect.DeepEqual(validated, []string{"Value1.Inner", "Inner.Value1"}) {
+		t.Fatalf("Unexpected validated paths: %v", validated)
+	}
+
+	err = NewConfig().AddFlags(XCF_ERROR_IF_STRUCT_FIELD_MISSING).CopyToExisting(src, &s1{})
+	xerr, isxerr := err.(*Error)
+	if !isxerr || xerr.Ctx.FieldsAsString() != "XValue1.Inner" {
+		t.Fatalf("Unexpected error path: %v", err)
+	}
+}
+
+func TestContextValuesConverter(t *testing.T) {
+	type sx struct {
+		Price string
+		Count int
+	}
+
+	// formats the decimal separator using the locale
+	converter := func(ctx *Context, value reflect.Value, t reflect.Type) (reflect.Value, error) {
+		s := fmt.Sprintf("%.2f", value.Interface())
+		if locale, _ := localeKey.Get(ctx); locale == "pt_BR" {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return reflect.ValueOf(s), nil
+	}
+
+	ret, err := NewConfig().SetContextValue(localeKey, "pt_BR").AddConverter(reflect.TypeOf(""), converter).
+		CopyToNew(map[string]interface{}{"Price": 1.5, "Count": 2}, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ret, sx{Price: "1,50", Count: 2}) {
+		t.Fatalf("Unexpected result: %+v", ret)
+	}
+
+	_, err = NewConfig().AddConverter(reflect.TypeOf(""), func(ctx *Context, value reflect.Value, t reflect.Type) (reflect.Value, error) {
+		return reflect.Value{}, errors.New("Invalid price")
+	}).CopyToNew(map[string]interface{}{"Price": 1.5}, reflect.TypeOf(sx{}))
+	if err == nil || err.Error() != "Invalid price [Price]" {
+		t.Fatalf("Converter error should have been returned: %v", err)
+	}
+}
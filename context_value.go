@@ -0,0 +1,32 @@
+package goxcopy
+
+// Typed key of a user value carried on the Context.
+type ContextKey[T any] struct {
+	name string
+}
+
+// Creates a new typed key. Each call returns a different key, even if the name is the same.
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// The name of the key, for debugging
+func (k *ContextKey[T]) String() string {
+	return k.name
+}
+
+// Sets the value for this key on the context.
+func (k *ContextKey[T]) Set(ctx *Context, value T) {
+	ctx.SetValue(k, value)
+}
+
+// Returns the value for this key on the context, and whether it was set.
+func (k *ContextKey[T]) Get(ctx *Context) (T, bool) {
+	if v, ok := ctx.LookupValue(k); ok {
+		if tv, ok := v.(T); ok {
+			return tv, true
+		}
+	}
+	var zero T
+	return zero, false
+}
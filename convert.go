@@ -0,0 +1,26 @@
+package goxcopy
+
+import "reflect"
+
+// Converts a primitive source value to the destination type. Unlike the converters of RprimConfig,
+// it receives the context, so it can use its user values, like a locale.
+type ConverterFunc func(ctx *Context, value reflect.Value, t reflect.Type) (reflect.Value, error)
+
+// Converts the primitive value to the type, using the converter added for the type, or RprimConfig.
+// Values that are already of the type are not passed to the converter.
+func (c *Config) convert(ctx *Context, value reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if converter, ok := c.Converters[t]; ok {
+		for value.Kind() == reflect.Interface && !value.IsNil() {
+			value = value.Elem()
+		}
+		if !value.IsValid() || value.Type() == t {
+			return c.RprimConfig.Convert(value, t)
+		}
+		ret, err := converter(ctx, value, t)
+		if err != nil {
+			return reflect.Value{}, wrapError(err, ctx)
+		}
+		return ret, nil
+	}
+	return c.RprimConfig.Convert(value, t)
+}
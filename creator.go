@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/RangelReale/rprim"
 )
@@ -22,8 +23,69 @@ type Creator interface {
 	TryFastCopy(value reflect.Value) bool
 }
 
+// Checks done before setting each field of a container.
+func (c *Config) beforeSetField(ctx *Context) error {
+	if err := ctx.checkDone(); err != nil {
+		return err
+	}
+	return c.checkFieldLimits(ctx)
+}
+
+// Creates a new instance of the type copying the current value.
+// The copy is not part of the running copy operation, so it is not reported nor recorded.
+func (c *Config) duplicateValue(current reflect.Value, t reflect.Type) (reflect.Value, error) {
+	dc := c.Dup().AddFlags(XCF_DISABLE_VALIDATION | XCF_DISABLE_LIFECYCLE_HOOKS)
+	dc.Report = nil
+	dc.ChangeLog = nil
+	dc.Provenance = nil
+	return dc.xCopyToNew(NewContext(), current, t)
+}
+
+// Creates a custom Creator for the type.
+type CreatorFactory func(ctx *Context, c *Config, t reflect.Type) (Creator, error)
+
+// A custom creator registration. If Type is set, it must be equal to the destination type,
+// else the Match function is used to check if the registration applies to the type.
+type CreatorRegistration struct {
+	Type    reflect.Type
+	Match   func(t reflect.Type) bool
+	Factory CreatorFactory
+}
+
+func (r *CreatorRegistration) matches(t reflect.Type) bool {
+	if r.Type != nil {
+		return r.Type == t
+	}
+	return r.Match != nil && r.Match(t)
+}
+
+// Register a custom creator factory for the destination type.
+func (c *Config) RegisterCreator(t reflect.Type, factory CreatorFactory) *Config {
+	c.Creators = append(c.Creators, &CreatorRegistration{Type: t, Factory: factory})
+	return c
+}
+
+// Register a custom creator factory for the destination types accepted by the match function.
+func (c *Config) RegisterCreatorFunc(match func(t reflect.Type) bool, factory CreatorFactory) *Config {
+	c.Creators = append(c.Creators, &CreatorRegistration{Match: match, Factory: factory})
+	return c
+}
+
+// Copy a value to a field of the destination, inside a running copy operation.
+// Custom creators must use this to convert the values passed to SetField.
+func (c *Config) XCopyField(ctx *Context, src reflect.Value, destType reflect.Type, currentValue reflect.Value) (reflect.Value, error) {
+	return c.internalXCopyUsingExistingIfValid(ctx, src, destType, currentValue)
+}
+
 // Gets a creator for a type.
 func (c *Config) GetCreator(ctx *Context, t reflect.Type) (Creator, error) {
+	// the last registered creators have priority
+	for i := len(c.Creators) - 1; i >= 0; i-- {
+		if c.Creators[i].matches(t) {
+			return c.Creators[i].Factory(ctx, c, t)
+		}
+	}
+
 	tkind := rprim.UnderliningTypeKind(t)
 
 	switch tkind {
@@ -46,11 +108,13 @@ func (c *Config) GetCreator(ctx *Context, t reflect.Type) (Creator, error) {
 //
 
 type copyCreator_Struct struct {
-	ctx      *Context
-	c        *Config
-	t        reflect.Type
-	isEnsure bool
-	v        reflect.Value
+	ctx       *Context
+	c         *Config
+	t         reflect.Type
+	isEnsure  bool
+	v         reflect.Value
+	setFields map[string]bool
+	lazy      lazyPointer
 }
 
 func (c *copyCreator_Struct) Type() reflect.Type {
@@ -66,6 +130,8 @@ func (c *copyCreator_Struct) SetCurrentValue(current reflect.Value) error {
 		return newError(fmt.Errorf("Destination is not of the same type (%s -> %s)", current.Type().String(), c.t.String()), c.ctx)
 	}
 	if current.IsValid() {
+		c.lazy.setCurrent(current)
+
 		// check if must write on the passed value
 		overwrite_existing := (c.c.Flags & XCF_OVERWRITE_EXISTING) == XCF_OVERWRITE_EXISTING
 		need_duplicate := !overwrite_existing
@@ -86,7 +152,7 @@ func (c *copyCreator_Struct) SetCurrentValue(current reflect.Value) error {
 		if need_duplicate {
 			if !overwrite_existing || (c.c.Flags&XCF_ALLOW_DUPLICATING_IF_NOT_SETTABLE) == XCF_ALLOW_DUPLICATING_IF_NOT_SETTABLE {
 				// Create a new instance copying the value
-				newValue, err := c.c.XCopyToNew(NewContext(), current, c.t)
+				newValue, err := c.c.duplicateValue(current, c.t)
 				if err != nil {
 					return err
 				}
@@ -101,10 +167,31 @@ func (c *copyCreator_Struct) SetCurrentValue(current reflect.Value) error {
 
 func (c *copyCreator_Struct) Create() (reflect.Value, error) {
 	c.ensureValueOrZero()
-	return c.v, nil
+	if c.c.Report != nil {
+		ut := rprim.UnderliningType(c.t)
+		for fi := 0; fi < ut.NumField(); fi++ {
+			f := ut.Field(fi)
+			if f.PkgPath != "" {
+				// skip unexported fields
+				continue
+			}
+			if fname := c.c.GetStructFieldName(f); fname != "" && !c.setFields[fname] {
+				fpath := c.ctx.pathStringAppending(reflect.ValueOf(fname))
+				c.c.reportUnset(fpath)
+				if c.c.isRequiredField(f, fpath) {
+					c.c.Report.addRequired(fpath)
+				}
+			}
+		}
+	}
+	return c.lazy.value(c.c, c.t, c.v), nil
 }
 
 func (c *copyCreator_Struct) SetField(index reflect.Value, value reflect.Value) error {
+	if err := c.c.beforeSetField(c.ctx); err != nil {
+		return err
+	}
+
 	fieldname, err := c.c.RprimConfig.ConvertToString(index)
 	if err != nil {
 		return err
@@ -123,16 +210,7 @@ func (c *copyCreator_Struct) SetField(index reflect.Value, value reflect.Value)
 
 	for fi := 0; fi < ut.NumField(); fi++ {
 		f := ut.Field(fi)
-		fname := f.Name
-
-		tag_fields := c.c.GetStructTagFields(f)
-		if len(tag_fields) > 0 {
-			if tag_fields[0] == "-" {
-				fname = "" // skip
-			} else {
-				fname = tag_fields[0]
-			}
-		}
+		fname := c.c.GetStructFieldName(f)
 
 		if fname == fieldname {
 			fieldType = f
@@ -142,8 +220,12 @@ func (c *copyCreator_Struct) SetField(index reflect.Value, value reflect.Value)
 	}
 	if !fieldTypeOk {
 		if (c.c.Flags & XCF_ERROR_IF_STRUCT_FIELD_MISSING) == XCF_ERROR_IF_STRUCT_FIELD_MISSING {
+			if suggest := similarNames(fieldname, c.c.GetStructFieldNames(ut)); len(suggest) > 0 {
+				return newError(fmt.Errorf("Field %s missing on struct, did you mean %s?", fieldname, strings.Join(suggest, " or ")), c.ctx)
+			}
 			return newError(fmt.Errorf("Field %s missing on struct", fieldname), c.ctx)
 		}
+		c.c.reportUnused(c.ctx)
 		return nil
 	}
 
@@ -152,12 +234,40 @@ func (c *copyCreator_Struct) SetField(index reflect.Value, value reflect.Value)
 		return newError(fmt.Errorf("Struct field %s is not settable", fieldname), c.ctx)
 	}
 
-	cv, err := c.c.internalXCopyUsingExistingIfValid(c.ctx, value, fieldType.Type, fieldValue)
-	if err != nil {
-		return err
+	if c.c.skipMerge(c.ctx, value, fieldValue) {
+		if !fieldValue.IsZero() {
+			// the destination already has a value
+			if c.setFields == nil {
+				c.setFields = make(map[string]bool)
+			}
+			c.setFields[fieldname] = true
+			c.c.reportPresent(c.ctx)
+		}
+		return nil
+	}
+
+	if strategy, ok := c.c.StructFieldOptionValue(fieldType, "merge"); ok {
+		c.ctx.setMergeStrategy(MergeStrategy(strategy))
+	}
+
+	var cv reflect.Value
+	if c.c.isMergePatchDelete(value) {
+		cv = reflect.Zero(fieldType.Type)
+	} else {
+		cv, err = c.c.internalXCopyUsingExistingIfValid(c.ctx, value, fieldType.Type, fieldValue)
+		if err != nil {
+			return err
+		}
 	}
 
 	fieldValue.Set(cv)
+	c.lazy.setValue(cv)
+
+	if c.setFields == nil {
+		c.setFields = make(map[string]bool)
+	}
+	c.setFields[fieldname] = true
+	c.c.reportUsed(c.ctx)
 	return nil
 }
 
@@ -189,11 +299,17 @@ func (c *copyCreator_Struct) ensureValueOrZero() {
 //
 
 type copyCreator_Map struct {
-	ctx      *Context
-	c        *Config
-	t        reflect.Type
-	isEnsure bool
-	v        reflect.Value
+	ctx        *Context
+	c          *Config
+	t          reflect.Type
+	isEnsure   bool
+	v          reflect.Value
+	hasCurrent bool
+	replace    bool
+	mirror     bool
+	// keys set by the source, for mirror mode
+	sourceKeys map[interface{}]bool
+	lazy       lazyPointer
 }
 
 func (c *copyCreator_Map) Type() reflect.Type {
@@ -210,6 +326,8 @@ func (c *copyCreator_Map) SetCurrentValue(current reflect.Value) error {
 	}
 
 	if current.IsValid() {
+		c.lazy.setCurrent(current)
+
 		// check if must write on the passed value
 		overwrite_existing := (c.c.Flags & XCF_OVERWRITE_EXISTING) == XCF_OVERWRITE_EXISTING
 		need_duplicate := !overwrite_existing
@@ -227,7 +345,7 @@ func (c *copyCreator_Map) SetCurrentValue(current reflect.Value) error {
 		if need_duplicate {
 			if !overwrite_existing || (c.c.Flags&XCF_ALLOW_DUPLICATING_IF_NOT_SETTABLE) == XCF_ALLOW_DUPLICATING_IF_NOT_SETTABLE {
 				// Create a new instance copying the value
-				newValue, err := c.c.XCopyToNew(NewContext(), current, c.t)
+				newValue, err := c.c.duplicateValue(current, c.t)
 				if err != nil {
 					return err
 				}
@@ -236,16 +354,31 @@ func (c *copyCreator_Map) SetCurrentValue(current reflect.Value) error {
 				return newError(fmt.Errorf("Map is not settable and duplicates are not allowed."), c.ctx)
 			}
 		}
+		c.hasCurrent = true
+		c.replace = c.c.getMergeStrategy(c.ctx) == MERGESTRATEGY_REPLACE
+		c.mirror = (c.c.Flags & XCF_MIRROR) == XCF_MIRROR
 	}
 	return nil
 }
 
 func (c *copyCreator_Map) Create() (reflect.Value, error) {
+	if c.replace {
+		if err := c.ensureValue(); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if c.mirror {
+		c.deleteMissingKeys()
+	}
 	c.ensureValueOrZero()
-	return c.v, nil
+	return c.lazy.value(c.c, c.t, c.v), nil
 }
 
 func (c *copyCreator_Map) SetField(index reflect.Value, value reflect.Value) error {
+	if err := c.c.beforeSetField(c.ctx); err != nil {
+		return err
+	}
+
 	ut := rprim.UnderliningType(c.t)
 
 	// convert index to the map index type
@@ -254,6 +387,13 @@ func (c *copyCreator_Map) SetField(index reflect.Value, value reflect.Value) err
 		return err
 	}
 
+	if c.mirror {
+		if c.sourceKeys == nil {
+			c.sourceKeys = make(map[interface{}]bool)
+		}
+		c.sourceKeys[mapindex.Interface()] = true
+	}
+
 	err = c.ensureValue()
 	if err != nil {
 		return err
@@ -267,11 +407,36 @@ func (c *copyCreator_Map) SetField(index reflect.Value, value reflect.Value) err
 		currentValue = cur
 	}
 
+	if c.c.skipMerge(c.ctx, value, currentValue) {
+		return nil
+	}
+
+	if c.c.isMergePatchDelete(value) {
+		if currentValue.IsValid() {
+			uv.SetMapIndex(mapindex, reflect.Value{})
+			c.c.reportUsed(c.ctx)
+			if c.hasCurrent {
+				c.c.recordChange(c.ctx, CHANGEOP_DELETEKEY, changeValue(currentValue), reflect.Value{})
+			}
+		}
+		return nil
+	}
+
 	// special case of map[x]interface{} to allow inner maps of the same type as this
 	target_type := ut.Elem()
 	if !((c.c.Flags & XCF_DISABLE_MAPOFINTERFACE_TARGET_RECURSION) == XCF_DISABLE_MAPOFINTERFACE_TARGET_RECURSION) {
 		if target_type.Kind() == reflect.Interface && KindHasFields(rprim.UnderliningValueKind(value)) {
 			target_type = ut
+			// merge on an existing inner map of the same type
+			if currentValue.IsValid() && currentValue.Kind() == reflect.Interface {
+				if currentValue.IsNil() || currentValue.Elem().Type() != ut {
+					currentValue = reflect.Value{}
+				} else {
+					inner := reflect.New(ut).Elem()
+					inner.Set(currentValue.Elem())
+					currentValue = inner
+				}
+			}
 		}
 	}
 
@@ -281,6 +446,11 @@ func (c *copyCreator_Map) SetField(index reflect.Value, value reflect.Value) err
 	}
 
 	uv.SetMapIndex(mapindex, cv)
+	c.lazy.setValue(cv)
+	c.c.reportUsed(c.ctx)
+	if c.hasCurrent && !currentValue.IsValid() {
+		c.c.recordChange(c.ctx, CHANGEOP_ADDKEY, nil, cv)
+	}
 	return nil
 }
 
@@ -298,7 +468,9 @@ func (c *copyCreator_Map) ensureValue() error {
 				return err
 			}
 		}
-		if last.IsNil() {
+		if c.replace && !last.IsNil() && !last.CanSet() {
+			last.Clear()
+		} else if c.replace || last.IsNil() {
 			last.Set(reflect.MakeMap(rprim.UnderliningType(c.t)))
 		}
 		c.isEnsure = true
@@ -317,11 +489,20 @@ func (c *copyCreator_Map) ensureValueOrZero() {
 //
 
 type copyCreator_Slice struct {
-	ctx      *Context
-	c        *Config
-	t        reflect.Type
-	isEnsure bool
-	v        reflect.Value
+	ctx        *Context
+	c          *Config
+	t          reflect.Type
+	isEnsure   bool
+	v          reflect.Value
+	hasCurrent bool
+	replace    bool
+	strategy   MergeStrategy
+	// length of the existing slice, for the append strategy
+	base   int
+	mirror bool
+	// number of elements of the source, for mirror mode
+	sourceLen int
+	lazy      lazyPointer
 }
 
 func (c *copyCreator_Slice) Type() reflect.Type {
@@ -355,6 +536,8 @@ func (c *copyCreator_Slice) SetCurrentValue(current reflect.Value) error {
 	}
 
 	if current.IsValid() {
+		c.lazy.setCurrent(current)
+
 		// check if must write on the passed value
 		overwrite_existing := (c.c.Flags & XCF_OVERWRITE_EXISTING) == XCF_OVERWRITE_EXISTING
 		need_duplicate := !overwrite_existing
@@ -372,7 +555,7 @@ func (c *copyCreator_Slice) SetCurrentValue(current reflect.Value) error {
 		if need_duplicate {
 			if !overwrite_existing || (c.c.Flags&XCF_ALLOW_DUPLICATING_IF_NOT_SETTABLE) == XCF_ALLOW_DUPLICATING_IF_NOT_SETTABLE {
 				// Create a new instance copying the value
-				newValue, err := c.c.XCopyToNew(NewContext(), current, c.t)
+				newValue, err := c.c.duplicateValue(current, c.t)
 				if err != nil {
 					return err
 				}
@@ -381,22 +564,46 @@ func (c *copyCreator_Slice) SetCurrentValue(current reflect.Value) error {
 				return newError(fmt.Errorf("Slice is not settable and duplicates are not allowed"), c.ctx)
 			}
 		}
+		c.hasCurrent = true
+		c.strategy = c.c.getMergeStrategy(c.ctx)
+		// merge patches replace the existing elements
+		c.replace = (c.c.Flags&XCF_MERGE_PATCH) == XCF_MERGE_PATCH || c.strategy == MERGESTRATEGY_REPLACE
+		// only elements set by index can be mirrored
+		c.mirror = (c.c.Flags&XCF_MIRROR) == XCF_MIRROR && (c.strategy == "" || c.strategy == MERGESTRATEGY_INDEX)
 	}
 	return nil
 }
 
 func (c *copyCreator_Slice) Create() (reflect.Value, error) {
+	if c.replace {
+		if err := c.ensureValue(); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if c.mirror {
+		if err := c.removeExtraElements(); err != nil {
+			return reflect.Value{}, err
+		}
+	}
 	c.ensureValueOrZero()
-	return c.v, nil
+	return c.lazy.value(c.c, c.t, c.v), nil
 }
 
 func (c *copyCreator_Slice) SetField(index reflect.Value, value reflect.Value) error {
+	if err := c.c.beforeSetField(c.ctx); err != nil {
+		return err
+	}
+
 	// convert index to int
 	sliceindex, err := c.c.RprimConfig.Convert(index, reflect.TypeOf(0))
 	if err != nil {
 		return err
 	}
 
+	if int(sliceindex.Int()) >= c.sourceLen {
+		c.sourceLen = int(sliceindex.Int()) + 1
+	}
+
 	err = c.ensureValue()
 	if err != nil {
 		return err
@@ -405,16 +612,35 @@ func (c *copyCreator_Slice) SetField(index reflect.Value, value reflect.Value) e
 	ut := rprim.UnderliningType(c.t)
 	uv := rprim.UnderliningValue(c.v)
 
+	switch {
+	case c.strategy == MERGESTRATEGY_APPEND:
+		sliceindex = reflect.ValueOf(c.base + int(sliceindex.Int()))
+	case c.strategy == MERGESTRATEGY_UNION || c.strategy.isByKey():
+		return c.setMatchingField(value)
+	}
+
+	if err := c.c.checkSliceLimits(c.ctx, int(sliceindex.Int()), uv.Len()); err != nil {
+		return err
+	}
+
+	currentValue := reflect.Value{}
+	if int(sliceindex.Int()) < uv.Len() {
+		currentValue = uv.Index(int(sliceindex.Int()))
+	}
+
+	if c.c.skipMerge(c.ctx, value, currentValue) {
+		return nil
+	}
+
 	// Add zero values until the index
+	appended := int(sliceindex.Int()) >= uv.Len()
 	for int(sliceindex.Int()) >= uv.Len() {
 		err = c.append()
 		if err != nil {
 			return err
 		}
 	}
-
-	currentValue := reflect.Value{}
-	if int(sliceindex.Int()) < uv.Len() {
+	if appended {
 		currentValue = uv.Index(int(sliceindex.Int()))
 	}
 
@@ -424,6 +650,64 @@ func (c *copyCreator_Slice) SetField(index reflect.Value, value reflect.Value) e
 	}
 
 	uv.Index(int(sliceindex.Int())).Set(cv)
+	c.lazy.setValue(cv)
+	c.c.reportUsed(c.ctx)
+	if c.hasCurrent && appended {
+		c.c.recordChange(c.ctx, CHANGEOP_APPEND, nil, cv)
+	}
+	return nil
+}
+
+// Sets the value on the existing element that matches it by the merge strategy, or appends it.
+func (c *copyCreator_Slice) setMatchingField(value reflect.Value) error {
+	ut := rprim.UnderliningType(c.t)
+	uv := rprim.UnderliningValue(c.v)
+
+	index := -1
+	currentValue := reflect.Value{}
+	if key, ok := c.strategy.byKey(); ok {
+		if srcKey, ok := c.c.elementKey(value, key); ok {
+			for i := 0; i < uv.Len(); i++ {
+				if curKey, ok := c.c.elementKey(uv.Index(i), key); ok && curKey == srcKey {
+					index = i
+					currentValue = uv.Index(i)
+					break
+				}
+			}
+		}
+	}
+
+	cv, err := c.c.internalXCopyUsingExistingIfValid(c.ctx, value, ut.Elem(), currentValue)
+	if err != nil {
+		return err
+	}
+
+	if c.strategy == MERGESTRATEGY_UNION {
+		for i := 0; i < uv.Len(); i++ {
+			if reflect.DeepEqual(uv.Index(i).Interface(), cv.Interface()) {
+				// already present
+				return nil
+			}
+		}
+	}
+
+	appended := index < 0
+	if appended {
+		if err := c.c.checkSliceLimits(c.ctx, uv.Len(), uv.Len()); err != nil {
+			return err
+		}
+		if err := c.append(); err != nil {
+			return err
+		}
+		index = uv.Len() - 1
+	}
+
+	uv.Index(index).Set(cv)
+	c.lazy.setValue(cv)
+	c.c.reportUsed(c.ctx)
+	if appended {
+		c.c.recordChange(c.ctx, CHANGEOP_APPEND, nil, cv)
+	}
 	return nil
 }
 
@@ -453,11 +737,18 @@ func (c *copyCreator_Slice) ensureValue() error {
 				return err
 			}
 		}
-		if last.Kind() != reflect.Array {
+		if c.replace {
+			if last.Kind() == reflect.Array {
+				last.Set(reflect.Zero(last.Type()))
+			} else {
+				last.Set(reflect.MakeSlice(rprim.UnderliningType(c.t), 0, 0))
+			}
+		} else if last.Kind() != reflect.Array {
 			if last.IsNil() {
 				last.Set(reflect.MakeSlice(rprim.UnderliningType(c.t), 0, 0))
 			}
 		}
+		c.base = last.Len()
 		c.isEnsure = true
 	}
 	return nil
@@ -478,8 +769,10 @@ type copyCreator_Primitive struct {
 	c   *Config
 	t   reflect.Type
 	//it  reflect.Type
-	isEnsure bool
-	v        reflect.Value
+	isEnsure   bool
+	v          reflect.Value
+	hasCurrent bool
+	old        interface{}
 }
 
 func (c *copyCreator_Primitive) Type() reflect.Type {
@@ -513,7 +806,7 @@ func (c *copyCreator_Primitive) SetCurrentValue(current reflect.Value) error {
 		if need_duplicate {
 			if !overwrite_existing || !((c.c.Flags & XCF_DENY_DUPLICATING_PRIMITIVE_IF_NOT_SETTABLE) == XCF_DENY_DUPLICATING_PRIMITIVE_IF_NOT_SETTABLE) {
 				// Create a new instance copying the value
-				newValue, err := c.c.XCopyToNew(NewContext(), current, c.t)
+				newValue, err := c.c.duplicateValue(current, c.t)
 				if err != nil {
 					return err
 				}
@@ -522,6 +815,10 @@ func (c *copyCreator_Primitive) SetCurrentValue(current reflect.Value) error {
 				return newError(fmt.Errorf("Primitive is not settable and duplicates are not allowed"), c.ctx)
 			}
 		}
+		c.hasCurrent = true
+		if c.c.ChangeLog != nil {
+			c.old = changeValue(current)
+		}
 	}
 	return nil
 }
@@ -541,11 +838,15 @@ func (c *copyCreator_Primitive) SetField(index reflect.Value, value reflect.Valu
 		return err
 	}
 
-	val, err := c.c.RprimConfig.Convert(value, c.t)
+	val, err := c.c.convert(c.ctx, value, c.t)
 	if err != nil {
 		return err
 	}
 
+	if err := c.c.checkStringLimits(c.ctx, val); err != nil {
+		return err
+	}
+
 	// check if settable
 	if c.v.CanSet() {
 		c.v.Set(val)
@@ -561,6 +862,11 @@ func (c *copyCreator_Primitive) SetField(index reflect.Value, value reflect.Valu
 	} else {
 		return newError(errors.New("The primitive value is not settable"), c.ctx)
 	}
+	if c.hasCurrent && c.c.ChangeLog != nil {
+		if newValue := changeValue(val); !reflect.DeepEqual(c.old, newValue) {
+			c.c.ChangeLog.add(c.ctx, CHANGEOP_SET, c.old, newValue)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,90 @@
+package goxcopy
+
+import (
+	"container/list"
+	"reflect"
+	"testing"
+)
+
+type listCreator struct {
+	ctx *Context
+	c   *Config
+	t   reflect.Type
+	l   *list.List
+}
+
+func (c *listCreator) Type() reflect.Type {
+	return c.t
+}
+
+func (c *listCreator) Create() (reflect.Value, error) {
+	if c.l == nil {
+		c.l = list.New()
+	}
+	return reflect.ValueOf(c.l), nil
+}
+
+func (c *listCreator) SetCurrentValue(current reflect.Value) error {
+	if !current.IsNil() {
+		c.l = current.Interface().(*list.List)
+	}
+	return nil
+}
+
+func (c *listCreator) SetField(index reflect.Value, value reflect.Value) error {
+	if c.l == nil {
+		c.l = list.New()
+	}
+	v, err := c.c.XCopyField(c.ctx, value, reflect.TypeOf(""), reflect.Value{})
+	if err != nil {
+		return err
+	}
+	c.l.PushBack(v.Interface())
+	return nil
+}
+
+func (c *listCreator) TryFastCopy(value reflect.Value) bool {
+	return false
+}
+
+func newListCreator(ctx *Context, c *Config, t reflect.Type) (Creator, error) {
+	return &listCreator{ctx: ctx, c: c, t: t}, nil
+}
+
+func TestCustomCreator(t *testing.T) {
+	type sx struct {
+		Value1 string
+		Value2 *list.List
+	}
+
+	src := map[string]interface{}{
+		"Value1": "x_value1",
+		"Value2": []int{1, 2, 3},
+	}
+
+	ret, err := NewConfig().RegisterCreator(reflect.TypeOf(&list.List{}), newListCreator).
+		CopyToNew(src, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := ret.(sx).Value2
+	if l == nil || l.Len() != 3 || l.Front().Value.(string) != "1" || l.Back().Value.(string) != "3" {
+		t.Fatal("Values are different")
+	}
+}
+
+func TestCustomCreatorFunc(t *testing.T) {
+	src := []int{1, 2}
+
+	ret, err := NewConfig().RegisterCreatorFunc(func(t reflect.Type) bool {
+		return t == reflect.TypeOf(&list.List{})
+	}, newListCreator).CopyToNew(src, reflect.TypeOf(&list.List{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.(*list.List).Len() != 2 {
+		t.Fatal("Values are different")
+	}
+}
@@ -0,0 +1,265 @@
+package goxcopy
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Operation of a difference between two values
+type DiffOp int
+
+const (
+	// The path exists only on the second value
+	DIFF_ADDED DiffOp = iota
+	// The path exists only on the first value
+	DIFF_REMOVED
+	// The path exists on both values, with different values
+	DIFF_MODIFIED
+)
+
+func (o DiffOp) String() string {
+	switch o {
+	case DIFF_ADDED:
+		return "added"
+	case DIFF_REMOVED:
+		return "removed"
+	case DIFF_MODIFIED:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// A difference between two values
+type Difference struct {
+	Path Path
+	Op   DiffOp
+	// The value on the first value, nil if added
+	From interface{}
+	// The value on the second value, nil if removed
+	To interface{}
+}
+
+// Returns the differences between two values, that may be of different types.
+// Structs and maps are compared by field name, using the struct tags and field map, and slices by index.
+func Diff(a interface{}, b interface{}) ([]Difference, error) {
+	return NewConfig().Diff(a, b)
+}
+
+// Returns the differences between two values, that may be of different types.
+// Structs and maps are compared by field name, using the struct tags and field map, and slices by index.
+func (c *Config) Diff(a interface{}, b interface{}) ([]Difference, error) {
+	return c.XDiff(NewContext(), reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// Returns the differences between two values, that may be of different types.
+// Structs and maps are compared by field name, using the struct tags and field map, and slices by index.
+func (c *Config) XDiff(ctx *Context, a reflect.Value, b reflect.Value) (ret []Difference, err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
+	// the normalized maps have string keys, record the original ones for the paths
+	ctx.diffPaths = make(map[string]Path)
+	defer func() {
+		ctx.diffPaths = nil
+	}()
+
+	na, err := c.normalizeValue(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	nb, err := c.normalizeValue(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	return c.diffNormalized(ctx, na, nb, nil), nil
+}
+
+// Converts the value to a tree of map[string]interface{}, []interface{} and primitive values,
+// naming the fields in the same way as a copy would.
+// Pointers are dereferenced, and nil pointers are returned as nil.
+func (c *Config) normalizeValue(ctx *Context, v reflect.Value) (interface{}, error) {
+	v = c.exportValue(v)
+	if !v.IsValid() || rprim.UnderliningValueIsNil(v) {
+		return nil, nil
+	}
+	// also dereference interfaces
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	source, err := c.GetSource(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		if !KindHasFields(source.Kind()) {
+			return c.normalizeValue(ctx, source.Value())
+		}
+		ret := make(map[string]interface{})
+		err := source.Fields(func(name reflect.Value, value reflect.Value) error {
+			return c.normalizeField(ctx, ret, name, value)
+		})
+		return ret, err
+	}
+
+	uv := v
+	switch uv.Kind() {
+	case reflect.Struct:
+		ret := make(map[string]interface{})
+		for i := 0; i < uv.NumField(); i++ {
+			if uv.Type().Field(i).PkgPath != "" {
+				// skip unexported fields
+				continue
+			}
+			if fname := c.GetStructFieldName(uv.Type().Field(i)); fname != "" {
+				if err := c.normalizeField(ctx, ret, reflect.ValueOf(fname), uv.Field(i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return ret, nil
+	case reflect.Map:
+		ret := make(map[string]interface{})
+		for _, k := range uv.MapKeys() {
+			if err := c.normalizeField(ctx, ret, k, uv.MapIndex(k)); err != nil {
+				return nil, err
+			}
+		}
+		return ret, nil
+	case reflect.Slice, reflect.Array:
+		ret := make([]interface{}, uv.Len())
+		for i := 0; i < uv.Len(); i++ {
+			ctx.PushField(reflect.ValueOf(i))
+			ctx.recordDiffPath()
+			nv, err := c.normalizeValue(ctx, uv.Index(i))
+			ctx.PopField()
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = nv
+		}
+		return ret, nil
+	}
+
+	return valueInterface(uv), nil
+}
+
+// Normalizes the field value and sets it on the map, renaming it using the field map.
+func (c *Config) normalizeField(ctx *Context, m map[string]interface{}, name reflect.Value, value reflect.Value) error {
+	if fieldmap := c.GetFieldMap(ctx.FieldsAsStringAppending(name)); fieldmap != nil && fieldmap.Fieldname != nil {
+		name = reflect.ValueOf(*fieldmap.Fieldname)
+	}
+	fname := FieldnameToString(name)
+
+	// keep the type of the original key on the path
+	for name.Kind() == reflect.Interface && !name.IsNil() {
+		name = name.Elem()
+	}
+	ctx.PushField(name)
+	ctx.recordDiffPath()
+	nv, err := c.normalizeValue(ctx, value)
+	ctx.PopField()
+	if err != nil {
+		return err
+	}
+	m[fname] = nv
+	return nil
+}
+
+func (c *Config) diffNormalized(ctx *Context, a interface{}, b interface{}, ret []Difference) []Difference {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			var keys []string
+			for k := range av {
+				keys = append(keys, k)
+			}
+			for k := range bv {
+				if _, ok := av[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				ctx.PushField(reflect.ValueOf(k))
+				ret = c.diffItem(ctx, av, bv, k, ret)
+				ctx.PopField()
+			}
+			return ret
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			for i := 0; i < len(av) || i < len(bv); i++ {
+				ctx.PushField(reflect.ValueOf(i))
+				switch {
+				case i >= len(bv):
+					ret = append(ret, Difference{Path: ctx.diffPath(), Op: DIFF_REMOVED, From: av[i]})
+				case i >= len(av):
+					ret = append(ret, Difference{Path: ctx.diffPath(), Op: DIFF_ADDED, To: bv[i]})
+				default:
+					ret = c.diffNormalized(ctx, av[i], bv[i], ret)
+				}
+				ctx.PopField()
+			}
+			return ret
+		}
+	}
+
+	if !c.diffEqual(a, b) {
+		ret = append(ret, Difference{Path: ctx.diffPath(), Op: DIFF_MODIFIED, From: a, To: b})
+	}
+	return ret
+}
+
+func (c *Config) diffItem(ctx *Context, a map[string]interface{}, b map[string]interface{}, key string, ret []Difference) []Difference {
+	av, aok := a[key]
+	bv, bok := b[key]
+	switch {
+	case !bok:
+		return append(ret, Difference{Path: ctx.diffPath(), Op: DIFF_REMOVED, From: av})
+	case !aok:
+		return append(ret, Difference{Path: ctx.diffPath(), Op: DIFF_ADDED, To: bv})
+	}
+	return c.diffNormalized(ctx, av, bv, ret)
+}
+
+// Records the current path of the original value being normalized, if recording.
+func (c *Context) recordDiffPath() {
+	if c.diffPaths == nil {
+		return
+	}
+	if _, ok := c.diffPaths[c.pathString()]; !ok {
+		c.diffPaths[c.pathString()] = c.Path()
+	}
+}
+
+// Returns the current path, with the map keys of the original values instead of the normalized ones.
+func (c *Context) diffPath() Path {
+	if path, ok := c.diffPaths[c.pathString()]; ok {
+		return path
+	}
+	return c.Path()
+}
+
+// Compares two primitive values, converting the second to the type of the first if needed.
+func (c *Config) diffEqual(a interface{}, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	if reflect.TypeOf(a).Kind() == reflect.Map || reflect.TypeOf(a).Kind() == reflect.Slice {
+		return false
+	}
+	cb, err := c.RprimConfig.Convert(reflect.ValueOf(b), reflect.TypeOf(a))
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, cb.Interface())
+}
@@ -0,0 +1,113 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffStructMap(t *testing.T) {
+	type s2 struct {
+		Value1 string
+	}
+	type sx struct {
+		Value1 string
+		Value2 int    `goxcopy:"value2"`
+		Value3 string `goxcopy:"-"`
+		Inner  *s2
+		Items  []string
+	}
+
+	a := &sx{
+		Value1: "x_value1",
+		Value2: 15,
+		Value3: "ignored",
+		Inner: &s2{
+			Value1: "inner_value1",
+		},
+		Items: []string{"one", "two"},
+	}
+
+	b := map[string]interface{}{
+		"Value1": "x_value1",
+		"value2": "15",
+		"Inner": map[string]interface{}{
+			"Value1": "changed",
+		},
+		"Items": []string{"one"},
+		"New":   true,
+	}
+
+	diff, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Difference{
+		{Path: Path{reflect.ValueOf("Inner"), reflect.ValueOf("Value1")}, Op: DIFF_MODIFIED, From: "inner_value1", To: "changed"},
+		{Path: Path{reflect.ValueOf("Items"), reflect.ValueOf(1)}, Op: DIFF_REMOVED, From: "two"},
+		{Path: Path{reflect.ValueOf("New")}, Op: DIFF_ADDED, To: true},
+	}
+
+	if len(diff) != len(expected) {
+		t.Fatalf("Unexpected differences: %+v", diff)
+	}
+	for i := range diff {
+		if diff[i].Path.String() != expected[i].Path.String() || diff[i].Op != expected[i].Op ||
+			!reflect.DeepEqual(diff[i].From, expected[i].From) || !reflect.DeepEqual(diff[i].To, expected[i].To) {
+			t.Fatalf("Unexpected difference %d: %+v", i, diff[i])
+		}
+	}
+
+	if diff[1].Path[1].Kind() != reflect.Int {
+		t.Fatal("Slice index path element should be an int")
+	}
+}
+
+func TestDiffMapKeyTypes(t *testing.T) {
+	a := map[int][]string{1: {"a"}, 2: {"b"}}
+	b := map[int][]string{1: {"a"}, 2: {"c"}, 3: {}}
+
+	diff, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff) != 2 || diff[0].Path.String() != "2.0" || diff[1].Path.String() != "3" {
+		t.Fatalf("Unexpected differences: %+v", diff)
+	}
+
+	// the path keeps the type of the map keys
+	if diff[0].Path[0].Kind() != reflect.Int || diff[0].Path[1].Kind() != reflect.Int || diff[1].Path[0].Kind() != reflect.Int {
+		t.Fatalf("Map key path elements should be ints: %+v", diff)
+	}
+}
+
+func TestDiffFieldMap(t *testing.T) {
+	type v1 struct {
+		Name string
+	}
+	type v2 struct {
+		FullName string
+	}
+
+	diff, err := NewConfig().SetFieldMap(map[string]*FieldMap{
+		"Name": NewFieldMap().SetFieldname("FullName"),
+	}).Diff(v1{Name: "x"}, v2{FullName: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff) != 0 {
+		t.Fatalf("Renamed fields should be equal: %+v", diff)
+	}
+}
+
+func TestDiffPanicContextFields(t *testing.T) {
+	type sx struct {
+		Inner panicSourceValue
+	}
+
+	ctx := NewContext()
+	_, err := panicSourceConfig().XDiff(ctx, reflect.ValueOf(sx{}), reflect.ValueOf(sx{}))
+	checkPanicContextFields(t, ctx, err, "Inner")
+}
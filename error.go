@@ -1,6 +1,9 @@
 package goxcopy
 
-import "fmt"
+import (
+	"fmt"
+	"runtime/debug"
+)
 
 // Error type
 type Error struct {
@@ -8,6 +11,8 @@ type Error struct {
 	Err error
 	// The context where the error occured
 	Ctx *Context
+	// Stack trace, only set if the error was created from a recovered panic
+	Stack []byte
 }
 
 func newError(err error, ctx *Context) *Error {
@@ -17,6 +22,19 @@ func newError(err error, ctx *Context) *Error {
 	}
 }
 
+// Creates an error from a recovered panic value, saving the current stack trace.
+func newPanicError(r interface{}, ctx *Context) *Error {
+	err, iserr := r.(error)
+	if !iserr {
+		err = fmt.Errorf("%v", r)
+	}
+	return &Error{
+		Err:   fmt.Errorf("Panic: %w", err),
+		Ctx:   ctx.Dup(),
+		Stack: debug.Stack(),
+	}
+}
+
 func (e *Error) Error() string {
 	if len(e.Ctx.Fields) > 0 {
 		return fmt.Sprintf("%s [%s]", e.Err.Error(), e.Ctx.FieldsAsString())
@@ -24,3 +42,31 @@ func (e *Error) Error() string {
 		return e.Err.Error()
 	}
 }
+
+// Returns the underlining error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Adds the context to the error, if it is not already an *Error.
+func wrapError(err error, ctx *Context) error {
+	if _, isxerr := err.(*Error); isxerr {
+		return err
+	}
+	return newError(err, ctx)
+}
+
+// Recovers a panic and converts it to an *Error on the passed error pointer.
+// The context fields are restored to the passed length, as the ones pushed before the panic were never popped.
+// Must be called directly by defer.
+func (c *Config) recoverPanic(ctx *Context, fieldsLen int, err *error) {
+	if (c.Flags & XCF_DISABLE_PANIC_RECOVERY) == XCF_DISABLE_PANIC_RECOVERY {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = newPanicError(r, ctx)
+		if len(ctx.Fields) > fieldsLen {
+			ctx.Fields = ctx.Fields[:fieldsLen]
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package goxcopy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type panicCallback struct {
+	field string
+}
+
+func (c *panicCallback) BeginNew(ctx *Context, src reflect.Value, destType reflect.Type) {}
+func (c *panicCallback) EndNew(ctx *Context, src reflect.Value, destType reflect.Type)   {}
+func (c *panicCallback) PushField(ctx *Context, fieldname reflect.Value, src reflect.Value, dest Creator) {
+	if ctx.Path().String() == c.field {
+		panic(errors.New("callback panic"))
+	}
+}
+func (c *panicCallback) PopField(ctx *Context, fieldname reflect.Value, src reflect.Value, dest Creator) {
+}
+func (c *panicCallback) BeforeSetValue(ctx *Context, src reflect.Value, dest Creator, value reflect.Value) {
+}
+func (c *panicCallback) AfterSetValue(ctx *Context, src reflect.Value, dest Creator, value reflect.Value) {
+}
+
+func TestPanicRecovery(t *testing.T) {
+	type s2 struct {
+		Value1 string
+	}
+	type s1 struct {
+		Inner s2
+	}
+
+	src := &s1{
+		Inner: s2{
+			Value1: "value1",
+		},
+	}
+
+	_, err := NewConfig().SetCallback(&panicCallback{field: "Inner.Value1"}).CopyToNew(src, reflect.TypeOf(&s1{}))
+	if err == nil {
+		t.Fatal("Panic should have been returned as error")
+	}
+
+	xerr, isxerr := err.(*Error)
+	if !isxerr {
+		t.Fatalf("Error should be *Error, is %T", err)
+	}
+
+	if xerr.Ctx.FieldsAsString() != "Value1.Inner" {
+		t.Fatalf("Error path should be Value1.Inner, is %s", xerr.Ctx.FieldsAsString())
+	}
+
+	if len(xerr.Stack) == 0 {
+		t.Fatal("Error should have a stack trace")
+	}
+
+	if xerr.Unwrap() == nil || errors.Unwrap(xerr.Unwrap()).Error() != "callback panic" {
+		t.Fatal("Error should wrap the panic value")
+	}
+}
+
+func TestPanicRecoveryContextFields(t *testing.T) {
+	type s2 struct {
+		Value1 string
+	}
+	type s1 struct {
+		Inner s2
+	}
+
+	ctx := NewContext()
+	_, err := NewConfig().SetCallback(&panicCallback{field: "Inner.Value1"}).
+		XCopyToNew(ctx, reflect.ValueOf(&s1{Inner: s2{Value1: "value1"}}), reflect.TypeOf(&s1{}))
+	if err == nil {
+		t.Fatal("Panic should have been returned as error")
+	}
+
+	if err.(*Error).Ctx.FieldsAsString() != "Value1.Inner" {
+		t.Fatalf("Error path should be Value1.Inner, is %s", err.(*Error).Ctx.FieldsAsString())
+	}
+
+	if len(ctx.Fields) != 0 {
+		t.Fatalf("Context fields should have been restored, are %s", ctx.FieldsAsString())
+	}
+
+	// the context can be reused
+	ret, err := NewConfig().XCopyToNew(ctx, reflect.ValueOf(&s1{Inner: s2{Value1: "value1"}}), reflect.TypeOf(s1{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret.Interface().(s1).Inner.Value1 != "value1" {
+		t.Fatalf("Unexpected result: %+v", ret.Interface())
+	}
+}
+
+func TestPanicRecoveryDisabled(t *testing.T) {
+	src := map[string]string{
+		"value1": "x_value1",
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Panic should have been propagated")
+		}
+	}()
+
+	_, _ = NewConfig().AddFlags(XCF_DISABLE_PANIC_RECOVERY).SetCallback(&panicCallback{field: "value1"}).
+		CopyToNew(src, reflect.TypeOf(map[string]string{}))
+}
+
+// Value that panics when used as a source
+type panicSourceValue struct{}
+
+func panicSourceConfig() *Config {
+	return NewConfig().RegisterSource(reflect.TypeOf(panicSourceValue{}), func(ctx *Context, c *Config, v reflect.Value) (Source, error) {
+		panic(errors.New("source panic"))
+	})
+}
+
+// Checks that the panic was returned as an error with its path, and that the context fields were restored.
+func checkPanicContextFields(t *testing.T, ctx *Context, err error, path string) {
+	t.Helper()
+	xerr, isxerr := err.(*Error)
+	if !isxerr || xerr.Stack == nil {
+		t.Fatalf("Panic should have been returned as error: %v", err)
+	}
+	if xerr.Ctx.FieldsAsString() != path {
+		t.Fatalf("Error path should be %s, is %s", path, xerr.Ctx.FieldsAsString())
+	}
+	if len(ctx.Fields) != 0 {
+		t.Fatalf("Context fields should have been restored, are %s", ctx.FieldsAsString())
+	}
+}
@@ -0,0 +1,220 @@
+package goxcopy
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Syntax of slice indexes in flattened keys
+type FlattenIndexStyle int
+
+const (
+	// Indexes are path elements, like "hosts.0.name" (default)
+	FLATTENINDEX_SEPARATOR FlattenIndexStyle = iota
+	// Indexes are in brackets, like "hosts[0].name"
+	FLATTENINDEX_BRACKETS
+)
+
+// Format of the keys of flattened values
+type FlattenFormat struct {
+	// Separator of the path elements (default: ".")
+	Separator string
+	// Syntax of slice indexes
+	IndexStyle FlattenIndexStyle
+}
+
+// Creates a new default FlattenFormat
+func NewFlattenFormat() *FlattenFormat {
+	return &FlattenFormat{
+		Separator: ".",
+	}
+}
+
+func (c *Config) flattenFormat() *FlattenFormat {
+	if c.FlattenFormat == nil || c.FlattenFormat.Separator == "" {
+		ret := NewFlattenFormat()
+		if c.FlattenFormat != nil {
+			ret.IndexStyle = c.FlattenFormat.IndexStyle
+		}
+		return ret
+	}
+	return c.FlattenFormat
+}
+
+// Flattens the value to a map of keys with the full path of each value, like "db.hosts.0.name".
+// Fields are named in the same way as a copy would, and empty structs, maps and slices are kept as values.
+func Flatten(v interface{}) (map[string]interface{}, error) {
+	return NewConfig().Flatten(v)
+}
+
+// Flattens the value to a map of keys with the full path of each value, like "db.hosts.0.name".
+// Fields are named in the same way as a copy would, and empty structs, maps and slices are kept as values.
+func (c *Config) Flatten(v interface{}) (map[string]interface{}, error) {
+	return c.XFlatten(NewContext(), reflect.ValueOf(v))
+}
+
+// Flattens the value to a map of keys with the full path of each value, like "db.hosts.0.name".
+// Fields are named in the same way as a copy would, and empty structs, maps and slices are kept as values.
+func (c *Config) XFlatten(ctx *Context, v reflect.Value) (ret map[string]interface{}, err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
+	nv, err := c.normalizeValue(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	ret = make(map[string]interface{})
+	c.flattenNormalized(c.flattenFormat(), nv, "", ret)
+	return ret, nil
+}
+
+func (c *Config) flattenNormalized(format *FlattenFormat, v interface{}, prefix string, ret map[string]interface{}) {
+	switch nv := v.(type) {
+	case map[string]interface{}:
+		if len(nv) > 0 {
+			for k, fv := range nv {
+				key := k
+				if prefix != "" {
+					key = prefix + format.Separator + k
+				}
+				c.flattenNormalized(format, fv, key, ret)
+			}
+			return
+		}
+	case []interface{}:
+		if len(nv) > 0 {
+			for i, fv := range nv {
+				var key string
+				switch {
+				case format.IndexStyle == FLATTENINDEX_BRACKETS:
+					key = prefix + "[" + strconv.Itoa(i) + "]"
+				case prefix != "":
+					key = prefix + format.Separator + strconv.Itoa(i)
+				default:
+					key = strconv.Itoa(i)
+				}
+				c.flattenNormalized(format, fv, key, ret)
+			}
+			return
+		}
+	}
+	ret[prefix] = v
+}
+
+// Creates a new value of the type from a flattened map, like the ones returned by Flatten.
+// Elements whose keys are all the indexes from 0 are created as slices.
+func Unflatten(m map[string]interface{}, destType reflect.Type) (interface{}, error) {
+	return NewConfig().Unflatten(m, destType)
+}
+
+// Creates a new value of the type from a flattened map, like the ones returned by Flatten.
+// Elements whose keys are all the indexes from 0 are created as slices.
+func (c *Config) Unflatten(m map[string]interface{}, destType reflect.Type) (interface{}, error) {
+	ret, err := c.XUnflatten(NewContext(), m, destType)
+	if err != nil {
+		return nil, err
+	}
+	return ret.Interface(), nil
+}
+
+// Creates a new value of the type from a flattened map, like the ones returned by Flatten.
+// Elements whose keys are all the indexes from 0 are created as slices.
+func (c *Config) XUnflatten(ctx *Context, m map[string]interface{}, destType reflect.Type) (ret reflect.Value, err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
+	format := c.flattenFormat()
+
+	// sort the keys for deterministic errors
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tree := make(map[string]interface{})
+	for _, k := range keys {
+		if err := unflattenSet(tree, format.parseKey(k), m[k]); err != nil {
+			return reflect.Value{}, newError(err, ctx)
+		}
+	}
+
+	result := reflect.ValueOf(unflattenSlices(tree))
+	if result.Type().AssignableTo(destType) {
+		// the created tree is already of the destination type, and copying it to a map of interfaces
+		// would create maps instead of slices
+		ret = reflect.New(destType).Elem()
+		ret.Set(result)
+		return ret, nil
+	}
+	return c.XCopyToNew(ctx, result, destType)
+}
+
+// Splits a flattened key in its path elements
+func (f *FlattenFormat) parseKey(key string) []string {
+	var ret []string
+	for _, part := range strings.Split(key, f.Separator) {
+		if f.IndexStyle == FLATTENINDEX_BRACKETS {
+			// split indexes like "hosts[0][1]"
+			if open := strings.Index(part, "["); open >= 0 && strings.HasSuffix(part, "]") {
+				ret = append(ret, part[:open])
+				ret = append(ret, strings.Split(part[open+1:len(part)-1], "][")...)
+				continue
+			}
+		}
+		ret = append(ret, part)
+	}
+	if len(ret) > 0 && ret[0] == "" && len(ret) > 1 {
+		// indexes of a top-level slice, like "[0]"
+		ret = ret[1:]
+	}
+	return ret
+}
+
+func unflattenSet(tree map[string]interface{}, path []string, value interface{}) error {
+	for i, p := range path {
+		if i == len(path)-1 {
+			if _, exists := tree[p]; exists {
+				return fmt.Errorf("Conflicting flattened keys at %s", strings.Join(path, "."))
+			}
+			tree[p] = value
+			return nil
+		}
+		child, exists := tree[p]
+		if !exists {
+			child = make(map[string]interface{})
+			tree[p] = child
+		}
+		childMap, ismap := child.(map[string]interface{})
+		if !ismap {
+			return fmt.Errorf("Conflicting flattened keys at %s", strings.Join(path[:i+1], "."))
+		}
+		tree = childMap
+	}
+	return nil
+}
+
+// Converts the maps whose keys are all the indexes from 0 to slices
+func unflattenSlices(v interface{}) interface{} {
+	m, ismap := v.(map[string]interface{})
+	if !ismap {
+		return v
+	}
+	for k, fv := range m {
+		m[k] = unflattenSlices(fv)
+	}
+
+	if len(m) == 0 {
+		return m
+	}
+	slice := make([]interface{}, len(m))
+	for k, fv := range m {
+		index, err := strconv.Atoi(k)
+		if err != nil || index < 0 || index >= len(m) || strconv.Itoa(index) != k {
+			return m
+		}
+		slice[index] = fv
+	}
+	return slice
+}
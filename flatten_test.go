@@ -0,0 +1,137 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+type flattenHost struct {
+	Name string `goxcopy:"name"`
+	Port int    `goxcopy:"port"`
+}
+
+type flattenDB struct {
+	Hosts []flattenHost `goxcopy:"hosts"`
+	User  string        `goxcopy:"user"`
+}
+
+type flattenConfig struct {
+	DB    flattenDB         `goxcopy:"db"`
+	Debug bool              `goxcopy:"debug"`
+	Tags  map[string]string `goxcopy:"tags"`
+}
+
+func newFlattenConfig() flattenConfig {
+	return flattenConfig{
+		DB: flattenDB{
+			Hosts: []flattenHost{
+				{Name: "h1", Port: 5432},
+				{Name: "h2", Port: 5433},
+			},
+			User: "admin",
+		},
+		Debug: true,
+		Tags:  map[string]string{},
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	ret, err := Flatten(newFlattenConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"db.hosts.0.name": "h1",
+		"db.hosts.0.port": 5432,
+		"db.hosts.1.name": "h2",
+		"db.hosts.1.port": 5433,
+		"db.user":         "admin",
+		"debug":           true,
+		"tags":            map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(ret, expected) {
+		t.Fatalf("Unexpected flattened value: %v", ret)
+	}
+
+	back, err := Unflatten(ret, reflect.TypeOf(flattenConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, newFlattenConfig()) {
+		t.Fatalf("Unexpected unflattened value: %+v", back)
+	}
+}
+
+func TestFlattenFormat(t *testing.T) {
+	c := NewConfig().SetFlattenFormat(&FlattenFormat{Separator: "/", IndexStyle: FLATTENINDEX_BRACKETS})
+
+	ret, err := c.Flatten(newFlattenConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret["db/hosts[1]/name"] != "h2" {
+		t.Fatalf("Unexpected flattened value: %v", ret)
+	}
+
+	back, err := c.Unflatten(map[string]interface{}{
+		"db/hosts[0]/name": "x1",
+		"db/hosts[0]/port": "1000",
+		"db/user":          "x_user",
+	}, reflect.TypeOf(flattenConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := flattenConfig{
+		DB: flattenDB{
+			Hosts: []flattenHost{{Name: "x1", Port: 1000}},
+			User:  "x_user",
+		},
+	}
+	if !reflect.DeepEqual(back, expected) {
+		t.Fatalf("Unexpected unflattened value: %+v", back)
+	}
+}
+
+func TestUnflattenInterface(t *testing.T) {
+	back, err := Unflatten(map[string]interface{}{
+		"a.0":   "v0",
+		"a.1":   "v1",
+		"b.2":   "v2",
+		"c.d.e": 1,
+	}, reflect.TypeOf(map[string]interface{}{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"a": []interface{}{"v0", "v1"},
+		"b": map[string]interface{}{"2": "v2"},
+		"c": map[string]interface{}{"d": map[string]interface{}{"e": 1}},
+	}
+	if !reflect.DeepEqual(back, expected) {
+		t.Fatalf("Unexpected unflattened value: %#v", back)
+	}
+
+	_, err = Unflatten(map[string]interface{}{
+		"a":   1,
+		"a.b": 2,
+	}, reflect.TypeOf(map[string]interface{}{}))
+	if err == nil {
+		t.Fatal("Conflicting keys should be an error")
+	}
+}
+
+func TestFlattenPanicContextFields(t *testing.T) {
+	type sx struct {
+		Inner panicSourceValue
+	}
+
+	ctx := NewContext()
+	_, err := panicSourceConfig().XFlatten(ctx, reflect.ValueOf(sx{}))
+	checkPanicContextFields(t, ctx, err, "Inner")
+
+	_, err = panicSourceConfig().XUnflatten(ctx, map[string]interface{}{"Inner": panicSourceValue{}}, reflect.TypeOf(sx{}))
+	checkPanicContextFields(t, ctx, err, "Inner")
+}
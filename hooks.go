@@ -0,0 +1,81 @@
+package goxcopy
+
+import "reflect"
+
+// BeforeCopyFrom can be implemented by destination types to be called before a source is copied to them.
+// It is only called for existing destination values that are changed in place, like on CopyToExisting
+// and merges.
+type BeforeCopyFrom interface {
+	BeforeCopyFrom(src interface{}) error
+}
+
+// AfterCopyTo can be implemented by source types to be called after they were copied to a destination.
+type AfterCopyTo interface {
+	AfterCopyTo(dest interface{}) error
+}
+
+// ValueExporter can be implemented by source types to be copied as a different value, like a map,
+// hiding their internal representation.
+type ValueExporter interface {
+	GoxcopyValue() interface{}
+}
+
+// Returns the value exported by the source, or the source itself if it doesn't implement ValueExporter.
+func (c *Config) exportValue(src reflect.Value) reflect.Value {
+	if (c.Flags & XCF_DISABLE_LIFECYCLE_HOOKS) == XCF_DISABLE_LIFECYCLE_HOOKS {
+		return src
+	}
+	if iv := findInterface(src, isValueExporter); iv != nil {
+		return reflect.ValueOf(iv.(ValueExporter).GoxcopyValue())
+	}
+	return src
+}
+
+func (c *Config) callBeforeCopyFrom(ctx *Context, src reflect.Value, currentValue reflect.Value) error {
+	if (c.Flags&XCF_DISABLE_LIFECYCLE_HOOKS) == XCF_DISABLE_LIFECYCLE_HOOKS ||
+		(c.Flags&XCF_OVERWRITE_EXISTING) != XCF_OVERWRITE_EXISTING {
+		// the existing value must not be changed
+		return nil
+	}
+	if iv := findInterface(currentValue, isBeforeCopyFrom); iv != nil {
+		if err := iv.(BeforeCopyFrom).BeforeCopyFrom(valueInterface(src)); err != nil {
+			return wrapError(err, ctx)
+		}
+	}
+	return nil
+}
+
+func (c *Config) callAfterCopyTo(ctx *Context, src reflect.Value, dest reflect.Value) error {
+	if (c.Flags & XCF_DISABLE_LIFECYCLE_HOOKS) == XCF_DISABLE_LIFECYCLE_HOOKS {
+		return nil
+	}
+	if iv := findInterface(src, isAfterCopyTo); iv != nil {
+		if err := iv.(AfterCopyTo).AfterCopyTo(valueInterface(dest)); err != nil {
+			return wrapError(err, ctx)
+		}
+	}
+	return nil
+}
+
+func isValueExporter(iv interface{}) bool {
+	_, ok := iv.(ValueExporter)
+	return ok
+}
+
+func isBeforeCopyFrom(iv interface{}) bool {
+	_, ok := iv.(BeforeCopyFrom)
+	return ok
+}
+
+func isAfterCopyTo(iv interface{}) bool {
+	_, ok := iv.(AfterCopyTo)
+	return ok
+}
+
+// Returns the value as an interface, or nil if not possible.
+func valueInterface(v reflect.Value) interface{} {
+	if v.IsValid() && v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package goxcopy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type ht_Exported struct {
+	values []string
+}
+
+func (h *ht_Exported) GoxcopyValue() interface{} {
+	return map[string]string{
+		"Value1": h.values[0],
+		"Value2": h.values[1],
+	}
+}
+
+type ht_Dest struct {
+	Value1 string
+	Value2 string
+	before int
+}
+
+func (h *ht_Dest) BeforeCopyFrom(src interface{}) error {
+	h.before++
+	h.Value2 = strings.ToUpper(h.Value2)
+	return nil
+}
+
+type ht_Source struct {
+	Value1 string
+	dest   *interface{}
+}
+
+func (h ht_Source) AfterCopyTo(dest interface{}) error {
+	*h.dest = dest
+	return nil
+}
+
+func TestValueExporter(t *testing.T) {
+	src := &ht_Exported{
+		values: []string{"x_value1", "x_value2"},
+	}
+
+	ret, err := CopyToNew(src, reflect.TypeOf(ht_Dest{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.(ht_Dest).Value1 != "x_value1" || ret.(ht_Dest).Value2 != "x_value2" {
+		t.Fatal("Values are different")
+	}
+}
+
+func TestBeforeCopyFrom(t *testing.T) {
+	dst := &ht_Dest{
+		Value2: "d_value2",
+	}
+
+	err := CopyToExisting(map[string]string{"Value1": "x_value1"}, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.before != 1 || dst.Value1 != "x_value1" || dst.Value2 != "D_VALUE2" {
+		t.Fatal("BeforeCopyFrom was not called")
+	}
+}
+
+func TestAfterCopyTo(t *testing.T) {
+	var dest interface{}
+	src := ht_Source{
+		Value1: "x_value1",
+		dest:   &dest,
+	}
+
+	ret, err := CopyToNew(src, reflect.TypeOf(map[string]interface{}{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(dest, ret) {
+		t.Fatal("AfterCopyTo was not called with the destination value")
+	}
+}
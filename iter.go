@@ -0,0 +1,94 @@
+package goxcopy
+
+import (
+	"iter"
+	"reflect"
+)
+
+// Source for range-over-func iterators. iter.Seq[V] is copied like a slice, and iter.Seq2[K, V] like a map.
+type seqSource struct {
+	v    reflect.Value
+	kind reflect.Kind
+}
+
+// Returns a source for the value if its type has the signature of iter.Seq or iter.Seq2, or nil if not.
+func newSeqSource(v reflect.Value) Source {
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return nil
+	}
+	yt := t.In(0)
+	if yt.Kind() != reflect.Func || yt.NumOut() != 1 || yt.Out(0).Kind() != reflect.Bool {
+		return nil
+	}
+	switch yt.NumIn() {
+	case 1:
+		return &seqSource{v: v, kind: reflect.Slice}
+	case 2:
+		return &seqSource{v: v, kind: reflect.Map}
+	}
+	return nil
+}
+
+func (s *seqSource) Kind() reflect.Kind {
+	return s.kind
+}
+
+func (s *seqSource) Fields(fn func(name reflect.Value, value reflect.Value) error) error {
+	if s.v.IsNil() {
+		return nil
+	}
+
+	var err error
+	index := 0
+	yield := reflect.MakeFunc(s.v.Type().In(0), func(args []reflect.Value) []reflect.Value {
+		if s.kind == reflect.Slice {
+			err = fn(reflect.ValueOf(index), args[0])
+			index++
+		} else {
+			err = fn(args[0], args[1])
+		}
+		// stop the iteration on error
+		return []reflect.Value{reflect.ValueOf(err == nil)}
+	})
+	s.v.Call([]reflect.Value{yield})
+	return err
+}
+
+func (s *seqSource) Value() reflect.Value {
+	return s.v
+}
+
+// Copy a source variable to a new []V, returning an iterator over its values.
+// The src variable is never changed in any circunstance.
+func CopyToSeq[V any](src interface{}) (iter.Seq[V], error) {
+	ret, err := CopyToNew(src, reflect.TypeOf([]V{}))
+	if err != nil {
+		return nil, err
+	}
+	values := ret.([]V)
+	return func(yield func(V) bool) {
+		for _, v := range values {
+			if !yield(v) {
+				return
+			}
+		}
+	}, nil
+}
+
+// Copy a source variable to a new map[K]V, returning an iterator over its keys and values.
+// The src variable is never changed in any circunstance.
+func CopyToSeq2[K comparable, V any](src interface{}) (iter.Seq2[K, V], error) {
+	ret, err := CopyToNew(src, reflect.TypeOf(map[K]V{}))
+	if err != nil {
+		return nil, err
+	}
+	values := ret.(map[K]V)
+	return func(yield func(K, V) bool) {
+		for k, v := range values {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}, nil
+}
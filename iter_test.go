@@ -0,0 +1,107 @@
+package goxcopy
+
+import (
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSeqToSlice(t *testing.T) {
+	src := slices.Values([]int{1, 2, 3})
+
+	ret, err := CopyToNew(src, reflect.TypeOf([]string{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ret, []string{"1", "2", "3"}) {
+		t.Fatal("Values are different")
+	}
+}
+
+func TestSeqLazy(t *testing.T) {
+	produced := 0
+	var src iter.Seq[int] = func(yield func(int) bool) {
+		for i := 0; i < 5; i++ {
+			produced++
+			if !yield(i * 10) {
+				return
+			}
+		}
+	}
+
+	var dst [2]int
+	err := CopyToExisting(src, &dst)
+	if err == nil {
+		t.Fatal("Should have been error 'Arrays cannot be appended'")
+	}
+
+	if produced != 3 {
+		t.Fatalf("The iteration should have stopped on the first error, produced %d", produced)
+	}
+}
+
+func TestSeq2ToStruct(t *testing.T) {
+	type sx struct {
+		Value1 string
+		Value2 int
+	}
+
+	src := maps.All(map[string]interface{}{
+		"Value1": "x_value1",
+		"Value2": 15,
+	})
+
+	ret, err := CopyToNew(src, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.(sx).Value1 != "x_value1" || ret.(sx).Value2 != 15 {
+		t.Fatal("Values are different")
+	}
+}
+
+func TestCopyToSeq(t *testing.T) {
+	seq, err := CopyToSeq[int]([]string{"1", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(slices.Collect(seq), []int{1, 2}) {
+		t.Fatal("Values are different")
+	}
+
+	seq2, err := CopyToSeq2[string, string](map[string]int{"a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(maps.Collect(seq2), map[string]string{"a": "1"}) {
+		t.Fatal("Values are different")
+	}
+}
+
+func TestSeqNested(t *testing.T) {
+	type sx struct {
+		L []int
+		M map[string]int
+	}
+
+	// the sequences are held in interfaces
+	src := map[string]interface{}{
+		"L": slices.Values([]int{1, 2, 3}),
+		"M": maps.All(map[string]int{"a": 1}),
+	}
+
+	ret, err := CopyToNew(src, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ret, sx{L: []int{1, 2, 3}, M: map[string]int{"a": 1}}) {
+		t.Fatalf("Unexpected result: %+v", ret)
+	}
+}
@@ -0,0 +1,38 @@
+package goxcopy
+
+import (
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+// State of the lazy allocation of a pointer destination, for XCF_LAZY_POINTERS.
+type lazyPointer struct {
+	// the current value is a non-nil pointer, which is never reset
+	hasPointer bool
+	// a non-zero value was set on the destination
+	nonZero bool
+}
+
+func (l *lazyPointer) setCurrent(current reflect.Value) {
+	l.hasPointer = current.Kind() == reflect.Ptr && !rprim.UnderliningValueIsNil(current)
+}
+
+func (l *lazyPointer) setValue(value reflect.Value) {
+	if value.IsValid() && !value.IsZero() {
+		l.nonZero = true
+	}
+}
+
+// Returns the created value, or a nil pointer if the pointer was allocated but no non-zero value was set.
+func (l *lazyPointer) value(c *Config, t reflect.Type, v reflect.Value) reflect.Value {
+	if (c.Flags&XCF_LAZY_POINTERS) != XCF_LAZY_POINTERS || t.Kind() != reflect.Ptr || l.hasPointer || l.nonZero {
+		return v
+	}
+	if v.CanSet() {
+		// the current value was allocated in place
+		v.Set(reflect.Zero(t))
+		return v
+	}
+	return reflect.Zero(t)
+}
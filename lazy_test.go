@@ -0,0 +1,52 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLazyPointers(t *testing.T) {
+	type s2 struct {
+		Value1 string
+		Value2 *int
+	}
+	type s1 struct {
+		Inner1 *s2
+		Inner2 *s2
+		Map    *map[string]int
+		Slice  *[]string
+	}
+
+	src := map[string]interface{}{
+		"Inner1": map[string]interface{}{"Value1": "", "Value2": nil},
+		"Inner2": map[string]interface{}{"Value1": "x_value1"},
+		"Map":    map[string]int{"a": 0},
+		"Slice":  []string{""},
+	}
+
+	ret, err := NewConfig().AddFlags(XCF_LAZY_POINTERS).CopyToNew(src, reflect.TypeOf(s1{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs := ret.(s1)
+	if rs.Inner1 != nil || rs.Map != nil || rs.Slice != nil {
+		t.Fatalf("Pointers with only zero values should be nil: %+v", rs)
+	}
+	if rs.Inner2 == nil || rs.Inner2.Value1 != "x_value1" {
+		t.Fatalf("Pointer with a non-zero value should have been allocated: %+v", rs)
+	}
+
+	existing := &s2{}
+	dst := &s1{Inner2: existing}
+	err = NewConfig().AddFlags(XCF_LAZY_POINTERS).CopyToExisting(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Inner1 != nil {
+		t.Fatalf("Nil destination pointer should have been kept nil: %+v", dst.Inner1)
+	}
+	if dst.Inner2 != existing || existing.Value1 != "x_value1" {
+		t.Fatal("Existing pointer should have been reused")
+	}
+}
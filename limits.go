@@ -0,0 +1,65 @@
+package goxcopy
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Limits for copying untrusted input. Zero values mean no limit.
+type Limits struct {
+	// Maximum length of destination slices, the maximum index is this value minus one
+	MaxSliceLen int
+	// Maximum number of zero values that can be added to a slice to reach an index
+	MaxSliceGap int
+	// Maximum nesting depth of fields
+	MaxDepth int
+	// Maximum number of elements set in a single copy operation
+	MaxElements int
+	// Maximum length of destination strings
+	MaxStringLen int
+}
+
+func (c *Config) checkFieldLimits(ctx *Context) error {
+	if c.Limits == nil {
+		return nil
+	}
+	if c.Limits.MaxDepth > 0 && len(ctx.Fields) > c.Limits.MaxDepth {
+		return newError(fmt.Errorf("Maximum nesting depth of %d exceeded", c.Limits.MaxDepth), ctx)
+	}
+	if c.Limits.MaxElements > 0 {
+		ctx.elements++
+		if ctx.elements > c.Limits.MaxElements {
+			return newError(fmt.Errorf("Maximum number of elements of %d exceeded", c.Limits.MaxElements), ctx)
+		}
+	}
+	return nil
+}
+
+func (c *Config) checkSliceLimits(ctx *Context, index int, length int) error {
+	if c.Limits == nil {
+		return nil
+	}
+	if index < 0 {
+		return newError(fmt.Errorf("Slice index %d is negative", index), ctx)
+	}
+	if c.Limits.MaxSliceLen > 0 && index >= c.Limits.MaxSliceLen {
+		return newError(fmt.Errorf("Slice index %d exceeds the maximum slice length of %d", index, c.Limits.MaxSliceLen), ctx)
+	}
+	if c.Limits.MaxSliceGap > 0 && index-length > c.Limits.MaxSliceGap {
+		return newError(fmt.Errorf("Slice index %d would add %d empty items, the maximum is %d", index, index-length, c.Limits.MaxSliceGap), ctx)
+	}
+	return nil
+}
+
+func (c *Config) checkStringLimits(ctx *Context, value reflect.Value) error {
+	if c.Limits == nil || c.Limits.MaxStringLen <= 0 {
+		return nil
+	}
+	v := rprim.UnderliningValue(value)
+	if v.Kind() == reflect.String && v.Len() > c.Limits.MaxStringLen {
+		return newError(fmt.Errorf("String length of %d exceeds the maximum of %d", v.Len(), c.Limits.MaxStringLen), ctx)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package goxcopy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLimitsSlice(t *testing.T) {
+	src := map[string]int{
+		"999999999": 1,
+	}
+
+	_, err := NewConfig().SetLimits(&Limits{MaxSliceLen: 100}).CopyToNew(src, reflect.TypeOf([]int{}))
+	if err == nil || !strings.Contains(err.Error(), "maximum slice length") {
+		t.Fatalf("Slice length limit should have been an error: %v", err)
+	}
+
+	_, err = NewConfig().SetLimits(&Limits{MaxSliceGap: 10}).CopyToNew(map[string]int{"0": 1, "50": 2}, reflect.TypeOf([]int{}))
+	if err == nil || !strings.Contains(err.Error(), "empty items") {
+		t.Fatalf("Slice gap limit should have been an error: %v", err)
+	}
+
+	_, err = NewConfig().SetLimits(&Limits{MaxSliceLen: 100, MaxSliceGap: 10}).CopyToNew(map[string]int{"0": 1, "5": 2}, reflect.TypeOf([]int{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimitsDepthAndElements(t *testing.T) {
+	src := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{
+				"c": 1,
+			},
+		},
+	}
+
+	_, err := NewConfig().SetLimits(&Limits{MaxDepth: 2}).CopyToNew(src, reflect.TypeOf(map[string]interface{}{}))
+	if err == nil || err.Error() != "Maximum nesting depth of 2 exceeded [c.b.a]" {
+		t.Fatalf("Depth limit should have been an error: %v", err)
+	}
+
+	_, err = NewConfig().SetLimits(&Limits{MaxElements: 2}).CopyToNew(src, reflect.TypeOf(map[string]interface{}{}))
+	if err == nil || !strings.Contains(err.Error(), "Maximum number of elements") {
+		t.Fatalf("Elements limit should have been an error: %v", err)
+	}
+
+	_, err = NewConfig().SetLimits(&Limits{MaxDepth: 3, MaxElements: 3}).CopyToNew(src, reflect.TypeOf(map[string]interface{}{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimitsString(t *testing.T) {
+	type sx struct {
+		Value1 string
+	}
+
+	_, err := NewConfig().SetLimits(&Limits{MaxStringLen: 5}).CopyToNew(map[string]string{"Value1": "123456"}, reflect.TypeOf(sx{}))
+	if err == nil || !strings.Contains(err.Error(), "String length") {
+		t.Fatalf("String limit should have been an error: %v", err)
+	}
+}
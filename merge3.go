@@ -0,0 +1,188 @@
+package goxcopy
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Value of a Conflict side where the path doesn't exist, like a removed map key.
+// A ConflictResolver can return it to remove the path from the result.
+type Merge3Missing struct{}
+
+// A conflict of a three-way merge, where both sides changed the same path to different values.
+// The values are normalized in the same way as Diff.
+type Conflict struct {
+	Path   Path
+	Base   interface{}
+	Ours   interface{}
+	Theirs interface{}
+	// The value used on the result
+	Resolved interface{}
+}
+
+// Resolves a three-way merge conflict, returning the value to use on the result.
+type ConflictResolver func(ctx *Context, conflict *Conflict) (interface{}, error)
+
+// Resolves conflicts using "ours" value
+func ResolveOurs(ctx *Context, conflict *Conflict) (interface{}, error) {
+	return conflict.Ours, nil
+}
+
+// Resolves conflicts using "theirs" value
+func ResolveTheirs(ctx *Context, conflict *Conflict) (interface{}, error) {
+	return conflict.Theirs, nil
+}
+
+// Three-way merge of the changes from base made by ours and theirs, returning a new value of the type of ours.
+// Changes made by only one side, or equally by both, are applied. The paths changed differently by
+// both sides are returned as conflicts, and resolved by the Config.ConflictResolver.
+func Merge3(base interface{}, ours interface{}, theirs interface{}) (interface{}, []Conflict, error) {
+	return NewConfig().Merge3(base, ours, theirs)
+}
+
+// Three-way merge of the changes from base made by ours and theirs, returning a new value of the type of ours.
+// Changes made by only one side, or equally by both, are applied. The paths changed differently by
+// both sides are returned as conflicts, and resolved by the Config.ConflictResolver.
+func (c *Config) Merge3(base interface{}, ours interface{}, theirs interface{}) (interface{}, []Conflict, error) {
+	ret, conflicts, err := c.XMerge3(NewContext(), reflect.ValueOf(base), reflect.ValueOf(ours), reflect.ValueOf(theirs))
+	if err != nil {
+		return nil, conflicts, err
+	}
+	return ret.Interface(), conflicts, nil
+}
+
+// Three-way merge of the changes from base made by ours and theirs, returning a new value of the type of ours.
+// Changes made by only one side, or equally by both, are applied. The paths changed differently by
+// both sides are returned as conflicts, and resolved by the Config.ConflictResolver.
+func (c *Config) XMerge3(ctx *Context, base reflect.Value, ours reflect.Value, theirs reflect.Value) (ret reflect.Value, conflicts []Conflict, err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
+	nbase, err := c.normalizeValue(ctx, base)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	nours, err := c.normalizeValue(ctx, ours)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	ntheirs, err := c.normalizeValue(ctx, theirs)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+
+	merged, err := c.merge3Normalized(ctx, nbase, nours, ntheirs, &conflicts)
+	if err != nil {
+		return reflect.Value{}, conflicts, err
+	}
+
+	if _, missing := merged.(Merge3Missing); missing || merged == nil {
+		return reflect.Zero(ours.Type()), conflicts, nil
+	}
+
+	// the normalized values already have the field map applied
+	dc := c.Dup()
+	dc.FieldMap = nil
+	ret, err = dc.XCopyToNew(ctx, reflect.ValueOf(merged), ours.Type())
+	return ret, conflicts, err
+}
+
+func (c *Config) merge3Normalized(ctx *Context, base interface{}, ours interface{}, theirs interface{}, conflicts *[]Conflict) (interface{}, error) {
+	switch {
+	case c.merge3Equal(ctx, ours, theirs), c.merge3Equal(ctx, base, theirs):
+		return ours, nil
+	case c.merge3Equal(ctx, base, ours):
+		return theirs, nil
+	}
+
+	// both sides changed, merge the fields if possible
+	om, isomap := ours.(map[string]interface{})
+	tm, istmap := theirs.(map[string]interface{})
+	if isomap && istmap {
+		bm, _ := base.(map[string]interface{})
+
+		keys := make(map[string]bool)
+		for _, m := range []map[string]interface{}{bm, om, tm} {
+			for k := range m {
+				keys[k] = true
+			}
+		}
+		var sortedKeys []string
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+
+		ret := make(map[string]interface{})
+		for _, k := range sortedKeys {
+			ctx.PushField(reflect.ValueOf(k))
+			v, err := c.merge3Normalized(ctx, merge3MapValue(bm, k), merge3MapValue(om, k), merge3MapValue(tm, k), conflicts)
+			ctx.PopField()
+			if err != nil {
+				return nil, err
+			}
+			if _, missing := v.(Merge3Missing); !missing {
+				ret[k] = v
+			}
+		}
+		return ret, nil
+	}
+
+	os, isoslice := ours.([]interface{})
+	ts, istslice := theirs.([]interface{})
+	if isoslice && istslice && len(os) == len(ts) {
+		bs, _ := base.([]interface{})
+
+		ret := make([]interface{}, len(os))
+		for i := range os {
+			var bv interface{} = Merge3Missing{}
+			if i < len(bs) {
+				bv = bs[i]
+			}
+			ctx.PushField(reflect.ValueOf(i))
+			v, err := c.merge3Normalized(ctx, bv, os[i], ts[i], conflicts)
+			ctx.PopField()
+			if err != nil {
+				return nil, err
+			}
+			if _, missing := v.(Merge3Missing); missing {
+				v = nil
+			}
+			ret[i] = v
+		}
+		return ret, nil
+	}
+
+	conflict := Conflict{
+		Path:     ctx.Path(),
+		Base:     base,
+		Ours:     ours,
+		Theirs:   theirs,
+		Resolved: ours,
+	}
+	if c.ConflictResolver != nil {
+		resolved, err := c.ConflictResolver(ctx, &conflict)
+		if err != nil {
+			return nil, wrapError(err, ctx)
+		}
+		conflict.Resolved = resolved
+	}
+	*conflicts = append(*conflicts, conflict)
+	return conflict.Resolved, nil
+}
+
+func merge3MapValue(m map[string]interface{}, key string) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return Merge3Missing{}
+}
+
+// Compares normalized values, which may be missing
+func (c *Config) merge3Equal(ctx *Context, a interface{}, b interface{}) bool {
+	_, amissing := a.(Merge3Missing)
+	_, bmissing := b.(Merge3Missing)
+	if amissing || bmissing {
+		return amissing && bmissing
+	}
+	return len(c.diffNormalized(ctx, a, b, nil)) == 0
+}
@@ -0,0 +1,101 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+type merge3Server struct {
+	Host    string            `goxcopy:"host"`
+	Port    int               `goxcopy:"port"`
+	Options map[string]string `goxcopy:"options"`
+}
+
+func TestMerge3(t *testing.T) {
+	base := merge3Server{
+		Host: "localhost",
+		Port: 80,
+		Options: map[string]string{
+			"o1": "v1",
+			"o2": "v2",
+		},
+	}
+	ours := merge3Server{
+		Host: "ours.example.com",
+		Port: 80,
+		Options: map[string]string{
+			"o1": "v1",
+			"o2": "ours_v2",
+			"o3": "v3",
+		},
+	}
+	theirs := merge3Server{
+		Host: "localhost",
+		Port: 8080,
+		Options: map[string]string{
+			"o2": "theirs_v2",
+		},
+	}
+
+	ret, conflicts, err := Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := merge3Server{
+		Host: "ours.example.com",
+		Port: 8080,
+		Options: map[string]string{
+			"o2": "ours_v2",
+			"o3": "v3",
+		},
+	}
+	if !reflect.DeepEqual(ret, expected) {
+		t.Fatalf("Unexpected merge result: %+v", ret)
+	}
+
+	if len(conflicts) != 1 || conflicts[0].Path.String() != "options.o2" ||
+		conflicts[0].Ours != "ours_v2" || conflicts[0].Theirs != "theirs_v2" {
+		t.Fatalf("Unexpected conflicts: %+v", conflicts)
+	}
+}
+
+func TestMerge3Resolver(t *testing.T) {
+	base := map[string]interface{}{"value1": "base", "value2": "base"}
+	ours := map[string]interface{}{"value1": "ours", "value2": "ours"}
+	theirs := map[string]interface{}{"value1": "theirs"}
+
+	ret, conflicts, err := NewConfig().SetConflictResolver(ResolveTheirs).Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %+v", conflicts)
+	}
+	if !reflect.DeepEqual(ret, map[string]interface{}{"value1": "theirs"}) {
+		t.Fatalf("Unexpected merge result: %+v", ret)
+	}
+
+	ret, _, err = NewConfig().SetConflictResolver(func(ctx *Context, conflict *Conflict) (interface{}, error) {
+		if _, missing := conflict.Theirs.(Merge3Missing); missing {
+			return conflict.Ours, nil
+		}
+		return conflict.Theirs, nil
+	}).Merge3(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ret, map[string]interface{}{"value1": "theirs", "value2": "ours"}) {
+		t.Fatalf("Unexpected merge result: %+v", ret)
+	}
+}
+
+func TestMerge3PanicContextFields(t *testing.T) {
+	type sx struct {
+		Inner panicSourceValue
+	}
+
+	ctx := NewContext()
+	_, _, err := panicSourceConfig().XMerge3(ctx, reflect.ValueOf(sx{}), reflect.ValueOf(sx{}), reflect.ValueOf(sx{}))
+	checkPanicContextFields(t, ctx, err, "Inner")
+}
@@ -0,0 +1,66 @@
+package goxcopy
+
+import (
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Policy of which source values are set on the destination. Policies can be combined.
+type MergePolicy uint
+
+const (
+	// Always set the source values (default)
+	MERGEPOLICY_OVERRIDE MergePolicy = 0
+	// Skip nil source pointers, interfaces, maps and slices
+	MERGEPOLICY_SKIP_NIL MergePolicy = 1
+	// Skip source values that are the zero value of their type, including nil
+	MERGEPOLICY_SKIP_ZERO MergePolicy = 2
+	// Only set destination values that are currently zero, like when merging on top of defaults.
+	// Structs, maps and slices are merged, so their missing values are still set.
+	MERGEPOLICY_FILL_MISSING MergePolicy = 4
+)
+
+// Returns the merge policy of the current path, which is the one of the nearest parent path
+// that has one set, or the global one.
+func (c *Config) getMergePolicy(ctx *Context) MergePolicy {
+	return nearestPathValue(ctx, c.MergePolicies, c.MergePolicy)
+}
+
+// Whether the source value must not be set on the current destination value, according to the merge policy.
+func (c *Config) skipMerge(ctx *Context, value reflect.Value, currentValue reflect.Value) bool {
+	policy := c.getMergePolicy(ctx)
+	if policy == MERGEPOLICY_OVERRIDE {
+		return false
+	}
+
+	v := value
+	for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if (policy&MERGEPOLICY_SKIP_NIL) == MERGEPOLICY_SKIP_NIL && isNilValue(v) {
+		return true
+	}
+	if (policy&MERGEPOLICY_SKIP_ZERO) == MERGEPOLICY_SKIP_ZERO && (!v.IsValid() || v.IsZero()) {
+		return true
+	}
+	if (policy&MERGEPOLICY_FILL_MISSING) == MERGEPOLICY_FILL_MISSING && currentValue.IsValid() && !currentValue.IsZero() {
+		// containers are merged to fill their missing values
+		if !v.IsValid() || !KindHasFields(rprim.UnderliningValueKind(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNilValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
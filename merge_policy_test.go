@@ -0,0 +1,127 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+type mergePolicyInner struct {
+	Host string
+	Port int
+}
+
+type mergePolicyStruct struct {
+	Name    string
+	Count   int
+	Enabled *bool
+	Inner   mergePolicyInner
+	Tags    []string
+}
+
+func TestMergePolicySkipZero(t *testing.T) {
+	enabled := true
+	dst := &mergePolicyStruct{
+		Name:    "default",
+		Count:   10,
+		Enabled: &enabled,
+		Inner:   mergePolicyInner{Host: "localhost", Port: 80},
+	}
+
+	err := NewConfig().SetMergePolicy(MERGEPOLICY_SKIP_ZERO).MergeToExisting(dst, &mergePolicyStruct{
+		Name:  "custom",
+		Inner: mergePolicyInner{Port: 8080},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &mergePolicyStruct{
+		Name:    "custom",
+		Count:   10,
+		Enabled: &enabled,
+		Inner:   mergePolicyInner{Host: "localhost", Port: 8080},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected merge result: %+v", dst)
+	}
+}
+
+func TestMergePolicySkipNil(t *testing.T) {
+	enabled := true
+	dst := &mergePolicyStruct{
+		Count:   10,
+		Enabled: &enabled,
+	}
+
+	err := NewConfig().SetMergePolicy(MERGEPOLICY_SKIP_NIL).MergeToExisting(dst, &mergePolicyStruct{
+		Name: "custom",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Enabled == nil || !*dst.Enabled {
+		t.Fatal("Nil pointer should have been skipped")
+	}
+	if dst.Name != "custom" || dst.Count != 0 {
+		t.Fatalf("Non-nil values should have been set: %+v", dst)
+	}
+}
+
+func TestMergePolicyFillMissing(t *testing.T) {
+	dst := &mergePolicyStruct{
+		Name:  "existing",
+		Inner: mergePolicyInner{Host: "existing_host"},
+		Tags:  []string{"t1"},
+	}
+
+	err := NewConfig().SetMergePolicy(MERGEPOLICY_FILL_MISSING).MergeToExisting(dst, &mergePolicyStruct{
+		Name:  "default",
+		Count: 5,
+		Inner: mergePolicyInner{Host: "localhost", Port: 80},
+		Tags:  []string{"d1", "d2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &mergePolicyStruct{
+		Name:  "existing",
+		Count: 5,
+		Inner: mergePolicyInner{Host: "existing_host", Port: 80},
+		Tags:  []string{"t1", "d2"},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected merge result: %+v", dst)
+	}
+}
+
+func TestMergePolicyPath(t *testing.T) {
+	dst := &mergePolicyStruct{
+		Name:  "existing",
+		Count: 10,
+		Inner: mergePolicyInner{Host: "existing_host", Port: 80},
+	}
+
+	err := NewConfig().SetMergePolicy(MERGEPOLICY_SKIP_ZERO).
+		SetPathMergePolicy("Inner", MERGEPOLICY_OVERRIDE).
+		MergeToExisting(dst, map[string]interface{}{
+			"Name":  "",
+			"Count": 20,
+			"Inner": map[string]interface{}{
+				"Host": "",
+			},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &mergePolicyStruct{
+		Name:  "existing",
+		Count: 20,
+		Inner: mergePolicyInner{Host: "", Port: 80},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected merge result: %+v", dst)
+	}
+}
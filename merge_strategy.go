@@ -0,0 +1,98 @@
+package goxcopy
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Strategy to merge a source slice or map on an existing one. Strategies are set per destination path
+// on the Config, or with the "merge" struct tag option, like `goxcopy:"plugins,merge=key:id"`.
+type MergeStrategy string
+
+const (
+	// Slices: set the elements by index (default)
+	MERGESTRATEGY_INDEX MergeStrategy = "index"
+	// Slices and maps: replace the whole existing value
+	MERGESTRATEGY_REPLACE MergeStrategy = "replace"
+	// Slices: append the source elements
+	MERGESTRATEGY_APPEND MergeStrategy = "append"
+	// Slices: append the source elements that are not already present
+	MERGESTRATEGY_UNION MergeStrategy = "union"
+	// Maps: merge the values of existing keys (default)
+	MERGESTRATEGY_DEEP MergeStrategy = "deep"
+)
+
+// Slices: merge the source elements on the existing elements with the same value of the key field,
+// appending the ones not found.
+func MergeByKey(field string) MergeStrategy {
+	return MergeStrategy("key:" + field)
+}
+
+func (s MergeStrategy) byKey() (string, bool) {
+	return strings.CutPrefix(string(s), "key:")
+}
+
+func (s MergeStrategy) isByKey() bool {
+	_, ok := s.byKey()
+	return ok
+}
+
+// Returns the merge strategy of the current path, from the config or from the struct tag.
+func (c *Config) getMergeStrategy(ctx *Context) MergeStrategy {
+	if len(c.MergeStrategies) == 0 && len(ctx.mergeStrategies) == 0 {
+		return ""
+	}
+	path := ctx.pathString()
+	if strategy, ok := c.MergeStrategies[path]; ok {
+		return strategy
+	}
+	return ctx.mergeStrategies[path]
+}
+
+// Sets the merge strategy of the current path from a struct tag.
+func (c *Context) setMergeStrategy(strategy MergeStrategy) {
+	if c.mergeStrategies == nil {
+		c.mergeStrategies = make(map[string]MergeStrategy)
+	}
+	c.mergeStrategies[c.pathString()] = strategy
+}
+
+// Returns the value of the key field of a struct or map element, as a string.
+func (c *Config) elementKey(v reflect.Value, key string) (string, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	var kv reflect.Value
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath == "" && c.GetStructFieldName(f) == key {
+				kv = v.Field(i)
+				break
+			}
+		}
+	case reflect.Map:
+		mk, err := c.RprimConfig.Convert(reflect.ValueOf(key), v.Type().Key())
+		if err != nil {
+			return "", false
+		}
+		kv = v.MapIndex(mk)
+	}
+	for kv.IsValid() && kv.Kind() == reflect.Interface && !kv.IsNil() {
+		kv = kv.Elem()
+	}
+	if !kv.IsValid() || isNilValue(kv) {
+		return "", false
+	}
+
+	ret, err := c.RprimConfig.ConvertToString(kv)
+	if err != nil {
+		return "", false
+	}
+	return ret, true
+}
@@ -0,0 +1,102 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+type mergeStrategyPlugin struct {
+	ID      string `goxcopy:"id"`
+	Enabled bool   `goxcopy:"enabled"`
+	Config  string `goxcopy:"config"`
+}
+
+type mergeStrategyStruct struct {
+	Plugins []mergeStrategyPlugin `goxcopy:"plugins,merge=key:id"`
+	Users   []string              `goxcopy:"users,merge=union"`
+	Paths   []string              `goxcopy:"paths"`
+	Labels  map[string]string     `goxcopy:"labels"`
+}
+
+func TestMergeStrategyTags(t *testing.T) {
+	dst := &mergeStrategyStruct{
+		Plugins: []mergeStrategyPlugin{
+			{ID: "p1", Enabled: true, Config: "c1"},
+			{ID: "p2", Enabled: true, Config: "c2"},
+		},
+		Users: []string{"u1", "u2"},
+	}
+
+	err := NewConfig().MergeToExisting(dst, map[string]interface{}{
+		"plugins": []interface{}{
+			map[string]interface{}{"id": "p2", "config": "c2_changed"},
+			map[string]interface{}{"id": "p3", "enabled": true},
+		},
+		"users": []string{"u2", "u3", "u3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &mergeStrategyStruct{
+		Plugins: []mergeStrategyPlugin{
+			{ID: "p1", Enabled: true, Config: "c1"},
+			{ID: "p2", Enabled: true, Config: "c2_changed"},
+			{ID: "p3", Enabled: true},
+		},
+		Users: []string{"u1", "u2", "u3"},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected merge result: %+v", dst)
+	}
+}
+
+func TestMergeStrategyConfig(t *testing.T) {
+	dst := &mergeStrategyStruct{
+		Users: []string{"u1", "u2"},
+		Paths: []string{"/a", "/b"},
+		Labels: map[string]string{
+			"l1": "v1",
+		},
+	}
+
+	err := NewConfig().
+		SetPathMergeStrategy("users", MERGESTRATEGY_REPLACE).
+		SetPathMergeStrategy("paths", MERGESTRATEGY_APPEND).
+		SetPathMergeStrategy("labels", MERGESTRATEGY_REPLACE).
+		MergeToExisting(dst, map[string]interface{}{
+			"users": []string{"u3"},
+			"paths": []string{"/c"},
+			"labels": map[string]string{
+				"l2": "v2",
+			},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &mergeStrategyStruct{
+		Users: []string{"u3"},
+		Paths: []string{"/a", "/b", "/c"},
+		Labels: map[string]string{
+			"l2": "v2",
+		},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected merge result: %+v", dst)
+	}
+}
+
+func TestMergeStrategyMergeToNew(t *testing.T) {
+	ret, err := NewConfig().SetPathMergeStrategy("paths", MERGESTRATEGY_APPEND).
+		MergeToNew(reflect.TypeOf(mergeStrategyStruct{}),
+			map[string]interface{}{"paths": []string{"/a"}},
+			map[string]interface{}{"paths": []string{"/b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ret.(mergeStrategyStruct).Paths, []string{"/a", "/b"}) {
+		t.Fatalf("Unexpected merge result: %v", ret.(mergeStrategyStruct).Paths)
+	}
+}
@@ -67,3 +67,45 @@ func TestMergeMapExisting(t *testing.T) {
 		t.Fatal("Result values are not the expected ones")
 	}
 }
+
+func TestMergeNilSourceKeepsExisting(t *testing.T) {
+	type pInner struct {
+		Value string
+	}
+	type pOuter struct {
+		Name string
+		In   *pInner
+		M    map[string]int
+		S    []int
+	}
+
+	dst := &pOuter{
+		In: &pInner{Value: "x"},
+		M:  map[string]int{"a": 1},
+		S:  []int{1},
+	}
+	err := MergeToExisting(dst, &pOuter{Name: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &pOuter{
+		Name: "m",
+		In:   &pInner{Value: "x"},
+		M:    map[string]int{"a": 1},
+		S:    []int{1},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Nil sources should not have changed the existing values: %+v", dst)
+	}
+
+	ret, err := MergeToNew(reflect.TypeOf(pOuter{}), *expected, pOuter{Name: "override"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected.Name = "override"
+	if !reflect.DeepEqual(ret, *expected) {
+		t.Fatalf("Nil sources should not have changed the defaults: %+v", ret)
+	}
+}
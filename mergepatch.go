@@ -0,0 +1,12 @@
+package goxcopy
+
+import "reflect"
+
+// Whether the source value deletes the destination value in merge patch mode.
+// Only untyped nil values, like a JSON null decoded to an interface{}, are deletions.
+func (c *Config) isMergePatchDelete(value reflect.Value) bool {
+	if (c.Flags & XCF_MERGE_PATCH) != XCF_MERGE_PATCH {
+		return false
+	}
+	return !value.IsValid() || (value.Kind() == reflect.Interface && value.IsNil())
+}
@@ -0,0 +1,83 @@
+package goxcopy
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	type s2 struct {
+		Value1 string `goxcopy:"value1"`
+		Value2 string `goxcopy:"value2"`
+	}
+	type s1 struct {
+		Title string                 `goxcopy:"title"`
+		Inner *s2                    `goxcopy:"inner"`
+		Tags  []string               `goxcopy:"tags"`
+		Extra map[string]interface{} `goxcopy:"extra"`
+	}
+
+	dst := &s1{
+		Title: "x_title",
+		Inner: &s2{
+			Value1: "x_value1",
+			Value2: "x_value2",
+		},
+		Tags: []string{"a", "b", "c"},
+		Extra: map[string]interface{}{
+			"keep":   "x_keep",
+			"delete": "x_delete",
+			"nested": map[string]interface{}{
+				"n1": 1,
+				"n2": 2,
+			},
+		},
+	}
+
+	var patch map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"title": null,
+		"inner": {"value2": "patched"},
+		"tags": ["d"],
+		"extra": {"delete": null, "nested": {"n1": null, "n3": 3}, "missing": null}
+	}`), &patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changeLog := NewChangeLog()
+	err = NewConfig().AddFlags(XCF_MERGE_PATCH).SetChangeLog(changeLog).MergeToExisting(dst, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &s1{
+		Inner: &s2{
+			Value1: "x_value1",
+			Value2: "patched",
+		},
+		Tags: []string{"d"},
+		Extra: map[string]interface{}{
+			"keep": "x_keep",
+			"nested": map[string]interface{}{
+				"n2": 2,
+				"n3": float64(3),
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected merge patch result: %+v", dst)
+	}
+
+	deleted := 0
+	for _, ch := range changeLog.Changes {
+		if ch.Op == CHANGEOP_DELETEKEY {
+			deleted++
+		}
+	}
+	if deleted != 2 {
+		t.Fatalf("Expected 2 deleted keys, got %d: %v", deleted, changeLog.Changes)
+	}
+}
@@ -0,0 +1,60 @@
+package goxcopy
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Deletes the keys of the existing map that were not set by the source.
+func (c *copyCreator_Map) deleteMissingKeys() {
+	uv := rprim.UnderliningValue(c.v)
+	if uv.Kind() != reflect.Map || uv.IsNil() {
+		return
+	}
+	for _, k := range uv.MapKeys() {
+		if c.sourceKeys[k.Interface()] {
+			continue
+		}
+		old := uv.MapIndex(k)
+		uv.SetMapIndex(k, reflect.Value{})
+
+		c.ctx.PushField(k)
+		c.c.recordChange(c.ctx, CHANGEOP_DELETEKEY, changeValue(old), reflect.Value{})
+		c.ctx.PopField()
+	}
+}
+
+// Removes the elements of the existing slice after the source length, or sets them to the zero value for arrays.
+func (c *copyCreator_Slice) removeExtraElements() error {
+	uv := rprim.UnderliningValue(c.v)
+	if (uv.Kind() != reflect.Slice && uv.Kind() != reflect.Array) || uv.Len() <= c.sourceLen {
+		return nil
+	}
+
+	for i := c.sourceLen; i < uv.Len(); i++ {
+		if uv.Kind() == reflect.Array && uv.Index(i).IsZero() {
+			continue
+		}
+		c.ctx.PushField(reflect.ValueOf(i))
+		if uv.Kind() == reflect.Array {
+			c.c.recordChange(c.ctx, CHANGEOP_SET, changeValue(uv.Index(i)), reflect.Zero(uv.Type().Elem()))
+		} else {
+			c.c.recordChange(c.ctx, CHANGEOP_REMOVE, changeValue(uv.Index(i)), reflect.Value{})
+		}
+		c.ctx.PopField()
+	}
+
+	if uv.Kind() == reflect.Array {
+		for i := c.sourceLen; i < uv.Len(); i++ {
+			uv.Index(i).Set(reflect.Zero(uv.Type().Elem()))
+		}
+		return nil
+	}
+	if !uv.CanSet() {
+		return newError(errors.New("Slice is not settable, cannot remove elements"), c.ctx)
+	}
+	uv.Set(uv.Slice(0, c.sourceLen))
+	return nil
+}
@@ -0,0 +1,55 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMirror(t *testing.T) {
+	type item struct {
+		Name  string
+		Value int
+	}
+	type s1 struct {
+		Items  []*item
+		Array  [3]int
+		Labels map[string]string
+	}
+
+	first := &item{Name: "first", Value: 1}
+	dst := &s1{
+		Items:  []*item{first, {Name: "second"}, {Name: "third"}},
+		Array:  [3]int{1, 2, 3},
+		Labels: map[string]string{"l1": "v1", "l2": "v2"},
+	}
+
+	changeLog := NewChangeLog()
+	err := NewConfig().AddFlags(XCF_MIRROR).SetChangeLog(changeLog).CopyToExisting(map[string]interface{}{
+		"Items":  []interface{}{map[string]interface{}{"Name": "first_changed"}},
+		"Array":  []int{10},
+		"Labels": map[string]string{"l2": "v2_changed"},
+	}, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &s1{
+		Items:  []*item{{Name: "first_changed", Value: 1}},
+		Array:  [3]int{10, 0, 0},
+		Labels: map[string]string{"l2": "v2_changed"},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected mirror result: %+v", dst)
+	}
+
+	if dst.Items[0] != first {
+		t.Fatal("Existing element should have been reused")
+	}
+
+	if len(changeLog.ChangesOn("Items.1")) != 1 || changeLog.ChangesOn("Items.1")[0].Op != CHANGEOP_REMOVE {
+		t.Fatalf("Removed element should have been recorded: %v", changeLog.Changes)
+	}
+	if len(changeLog.ChangesOn("Labels.l1")) != 1 || changeLog.ChangesOn("Labels.l1")[0].Op != CHANGEOP_DELETEKEY {
+		t.Fatalf("Deleted key should have been recorded: %v", changeLog.Changes)
+	}
+}
@@ -0,0 +1,101 @@
+package goxcopy
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Policy of how nil source values are copied. Nil values are untyped nil, and nil pointers,
+// interfaces, maps and slices. Empty maps and slices are not nil, and are copied as empty.
+type NilPolicy int
+
+const (
+	// A nil struct pointer, map or slice source, or any nil source into a struct, map or slice destination,
+	// leaves the existing destination untouched; other destinations are set to their zero value (default)
+	NILPOLICY_DEFAULT NilPolicy = iota
+	// A nil source sets the destination to its zero value, which is nil for pointers, interfaces, maps and slices
+	NILPOLICY_SET_ZERO
+	// A nil source leaves the existing destination untouched
+	NILPOLICY_KEEP
+	// A nil source is an error
+	NILPOLICY_ERROR
+)
+
+// Returns the nil policy of the current path, which is the one of the nearest parent path
+// that has one set, or the global one.
+func (c *Config) getNilPolicy(ctx *Context) NilPolicy {
+	return nearestPathValue(ctx, c.NilPolicies, c.NilPolicy)
+}
+
+// Copies a nil source value according to the nil policy
+func (c *Config) copyNil(ctx *Context, src reflect.Value, destType reflect.Type, currentValue reflect.Value) (reflect.Value, error) {
+	policy := c.getNilPolicy(ctx)
+	if policy == NILPOLICY_DEFAULT {
+		if isNilContainer(src) || KindHasFields(rprim.UnderliningTypeKind(destType)) {
+			policy = NILPOLICY_KEEP
+		} else {
+			policy = NILPOLICY_SET_ZERO
+		}
+	}
+
+	switch policy {
+	case NILPOLICY_KEEP:
+		if !currentValue.IsValid() {
+			return reflect.New(destType).Elem(), nil
+		}
+		if (c.Flags & XCF_OVERWRITE_EXISTING) != XCF_OVERWRITE_EXISTING {
+			// the result must be a new instance, like when the existing value is copied
+			return c.duplicateValue(currentValue, destType)
+		}
+		return currentValue, nil
+	case NILPOLICY_ERROR:
+		return reflect.Value{}, newError(errors.New("Source value is nil"), ctx)
+	}
+
+	// new values must be settable like the created ones
+	zero := reflect.New(destType).Elem()
+	if !currentValue.IsValid() {
+		return zero, nil
+	}
+	if !currentValue.IsZero() {
+		c.recordChange(ctx, CHANGEOP_SET, changeValue(currentValue), zero)
+	}
+
+	switch {
+	case currentValue.CanSet() && (c.Flags&XCF_OVERWRITE_EXISTING) == XCF_OVERWRITE_EXISTING:
+		currentValue.Set(zero)
+		return currentValue, nil
+	case currentValue.Kind() == reflect.Ptr && !currentValue.IsNil() && (c.Flags&XCF_OVERWRITE_EXISTING) == XCF_OVERWRITE_EXISTING:
+		// the destination of a top-level copy to existing, set the pointed value
+		currentValue.Elem().Set(reflect.Zero(currentValue.Elem().Type()))
+		return currentValue, nil
+	}
+	return zero, nil
+}
+
+// Whether the value is a nil pointer to a struct, map or slice, or a nil map or slice.
+func isNilContainer(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return KindHasFields(t.Kind())
+}
+
+// Creators of collections that can be set to an empty value, to copy empty sources as empty instead of nil.
+type emptyCreator interface {
+	ensureEmpty() error
+}
+
+func (c *copyCreator_Map) ensureEmpty() error {
+	return c.ensureValue()
+}
+
+func (c *copyCreator_Slice) ensureEmpty() error {
+	return c.ensureValue()
+}
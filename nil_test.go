@@ -0,0 +1,183 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+type nilPolicyInner struct {
+	Value string
+}
+
+type nilPolicyStruct struct {
+	String string
+	Ptr    *string
+	Inner  *nilPolicyInner
+	Map    map[string]int
+	Slice  []int
+	Any    interface{}
+}
+
+func newNilPolicyStruct() *nilPolicyStruct {
+	v := "x_ptr"
+	return &nilPolicyStruct{
+		String: "x_string",
+		Ptr:    &v,
+		Inner:  &nilPolicyInner{Value: "x_inner"},
+		Map:    map[string]int{"a": 1},
+		Slice:  []int{1},
+		Any:    5,
+	}
+}
+
+var nilPolicySource = map[string]interface{}{
+	"String": nil,
+	"Ptr":    nil,
+	"Inner":  nil,
+	"Map":    nil,
+	"Slice":  nil,
+	"Any":    nil,
+}
+
+func TestNilPolicyDefault(t *testing.T) {
+	dst := newNilPolicyStruct()
+	err := CopyToExisting(nilPolicySource, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the destinations that are not structs, maps or slices are set to the zero value
+	expected := newNilPolicyStruct()
+	expected.String = ""
+	expected.Ptr = nil
+	expected.Any = nil
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected default nil policy result: %+v", dst)
+	}
+
+	// nil struct pointers, maps and slices leave the existing values untouched
+	dst = newNilPolicyStruct()
+	err = CopyToExisting(&nilPolicyStruct{String: "x_changed"}, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = newNilPolicyStruct()
+	expected.String = "x_changed"
+	expected.Ptr = nil
+	expected.Any = nil
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected default nil policy result: %+v", dst)
+	}
+}
+
+func TestNilPolicySetZero(t *testing.T) {
+	dst := newNilPolicyStruct()
+	err := NewConfig().SetNilPolicy(NILPOLICY_SET_ZERO).CopyToExisting(nilPolicySource, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, &nilPolicyStruct{}) {
+		t.Fatalf("All fields should have been set to the zero value: %+v", dst)
+	}
+
+	// nil struct pointers too
+	dst = newNilPolicyStruct()
+	err = NewConfig().SetNilPolicy(NILPOLICY_SET_ZERO).CopyToExisting(&nilPolicyStruct{}, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst, &nilPolicyStruct{}) {
+		t.Fatalf("All fields should have been set to the zero value: %+v", dst)
+	}
+}
+
+func TestNilPolicyKeep(t *testing.T) {
+	dst := newNilPolicyStruct()
+	err := NewConfig().SetNilPolicy(NILPOLICY_KEEP).SetPathNilPolicy("String", NILPOLICY_SET_ZERO).
+		CopyToExisting(nilPolicySource, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := newNilPolicyStruct()
+	expected.String = ""
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Only the String field should have been changed: %+v", dst)
+	}
+}
+
+func TestNilPolicyError(t *testing.T) {
+	err := NewConfig().SetPathNilPolicy("Inner", NILPOLICY_ERROR).
+		CopyToExisting(nilPolicySource, newNilPolicyStruct())
+	if err == nil {
+		t.Fatal("Nil source should have been an error")
+	}
+	if xerr, isxerr := err.(*Error); !isxerr || xerr.Ctx.FieldsAsString() != "Inner" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestNilUntyped(t *testing.T) {
+	ret, err := CopyToNew(nil, reflect.TypeOf(nilPolicyStruct{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ret, nilPolicyStruct{}) {
+		t.Fatalf("Untyped nil should be copied as the zero value: %+v", ret)
+	}
+}
+
+func TestNilEmptyPreserved(t *testing.T) {
+	ret, err := CopyToNew(map[string]interface{}{
+		"Map":   map[string]int{},
+		"Slice": []int{},
+	}, reflect.TypeOf(nilPolicyStruct{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs := ret.(nilPolicyStruct)
+	if rs.Map == nil || len(rs.Map) != 0 || rs.Slice == nil || len(rs.Slice) != 0 {
+		t.Fatalf("Empty collections should be copied as empty: %#v", rs)
+	}
+}
+
+func TestNilPolicyNilPointerToCollection(t *testing.T) {
+	var pm *map[string]int
+	retm, err := CopyToNew(&pm, reflect.TypeOf(map[string]int{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retm.(map[string]int)) != 0 {
+		t.Fatalf("Unexpected map result: %#v", retm)
+	}
+
+	var ps *[]int
+	rets, err := CopyToNew(&ps, reflect.TypeOf([]int{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rets.([]int)) != 0 {
+		t.Fatalf("Unexpected slice result: %#v", rets)
+	}
+}
+
+func TestNilPolicyKeepUsingExisting(t *testing.T) {
+	m := map[string]int{"a": 1}
+	ret, err := CopyUsingExisting((map[string]int)(nil), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rm := ret.(map[string]int)
+	if !reflect.DeepEqual(rm, map[string]int{"a": 1}) {
+		t.Fatalf("Unexpected result: %v", rm)
+	}
+
+	// the result must be a new instance
+	rm["b"] = 2
+	if !reflect.DeepEqual(m, map[string]int{"a": 1}) {
+		t.Fatalf("Existing value should not have been changed: %v", m)
+	}
+}
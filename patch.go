@@ -0,0 +1,380 @@
+package goxcopy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A JSON Patch (RFC 6902) operation
+type PatchOperation struct {
+	// One of add, remove, replace, move, copy or test
+	Op string `json:"op"`
+	// JSON Pointer (RFC 6901) of the target location
+	Path string `json:"path"`
+	// JSON Pointer of the source location, for move and copy
+	From string `json:"from,omitempty"`
+	// Value for add, replace and test, converted to the target type in the same way as a copy
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Applies the JSON Patch operations to the target, which must be a non-nil pointer.
+// Struct fields are found by their element names, using the struct tags.
+func ApplyPatch(target interface{}, ops []PatchOperation) error {
+	return NewConfig().ApplyPatch(target, ops)
+}
+
+// Applies the JSON Patch operations to the target, which must be a non-nil pointer.
+// Struct fields are found by their element names, using the struct tags.
+func (c *Config) ApplyPatch(target interface{}, ops []PatchOperation) error {
+	return c.XApplyPatch(NewContext(), reflect.ValueOf(target), ops)
+}
+
+// Applies the JSON Patch operations to the target, which must be a non-nil pointer.
+// Struct fields are found by their element names, using the struct tags.
+// With XCF_ATOMIC, the operations are applied to a copy of the target, which is only set if all of them succeed.
+func (c *Config) XApplyPatch(ctx *Context, target reflect.Value, ops []PatchOperation) (err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return newError(errors.New("Patch target must be a non-nil pointer"), ctx)
+	}
+
+	work, wc := target, c
+	if (c.Flags & XCF_ATOMIC) == XCF_ATOMIC {
+		work, err = c.duplicateValue(target, target.Type())
+		if err != nil {
+			return err
+		}
+		wc = c.scratchConfig()
+	}
+
+	for _, op := range ops {
+		if err := wc.applyPatchOperation(ctx, work, op); err != nil {
+			return err
+		}
+	}
+
+	if (c.Flags & XCF_ATOMIC) == XCF_ATOMIC {
+		return c.commitValue(ctx, wc, target, work)
+	}
+	return nil
+}
+
+func (c *Config) applyPatchOperation(ctx *Context, target reflect.Value, op PatchOperation) error {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return newError(err, ctx)
+	}
+
+	switch op.Op {
+	case "add":
+		return c.patchSet(ctx, target, path, reflect.ValueOf(op.Value), true, false)
+	case "replace":
+		return c.patchSet(ctx, target, path, reflect.ValueOf(op.Value), false, true)
+	case "remove":
+		return c.patchRemove(ctx, target, path)
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return newError(err, ctx)
+		}
+		if op.Op == "move" && len(from) < len(path) && strings.HasPrefix(op.Path, op.From+"/") {
+			return newError(fmt.Errorf("Cannot move %s to one of its children", op.From), ctx)
+		}
+		value, err := c.patchGet(ctx, target, from)
+		if err != nil {
+			return err
+		}
+		// the source location may change or be removed, copy the value
+		value, err = c.duplicateValue(value, value.Type())
+		if err != nil {
+			return err
+		}
+		if op.Op == "move" {
+			if err := c.patchRemove(ctx, target, from); err != nil {
+				return err
+			}
+		}
+		return c.patchSet(ctx, target, path, value, true, false)
+	case "test":
+		value, err := c.patchGet(ctx, target, path)
+		if err != nil {
+			return err
+		}
+		diff, err := c.XDiff(ctx, value, reflect.ValueOf(op.Value))
+		if err != nil {
+			return err
+		}
+		if len(diff) > 0 {
+			return newError(fmt.Errorf("Patch test failed for %s", op.Path), ctx)
+		}
+		return nil
+	}
+	return newError(fmt.Errorf("Unknown patch operation: %s", op.Op), ctx)
+}
+
+// Parses a JSON Pointer into its unescaped tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("Invalid JSON pointer: %s", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// Sets the value at the path. If insert is true, slice elements are inserted instead of replaced.
+// If mustExist is true, the path must already exist.
+func (c *Config) patchSet(ctx *Context, target reflect.Value, path []string, value reflect.Value, insert bool, mustExist bool) error {
+	if len(path) == 0 {
+		// replace the whole target
+		nv, err := c.internalXCopyUsingExistingIfValid(ctx, value, target.Elem().Type(), reflect.Value{})
+		if err != nil {
+			return err
+		}
+		target.Elem().Set(nv)
+		return nil
+	}
+	_, err := c.patchModify(ctx, target, path, func(container reflect.Value, token string) error {
+		return c.patchSetChild(ctx, container, token, value, insert, mustExist)
+	})
+	return err
+}
+
+func (c *Config) patchRemove(ctx *Context, target reflect.Value, path []string) error {
+	if len(path) == 0 {
+		return newError(errors.New("Cannot remove the patch target"), ctx)
+	}
+	_, err := c.patchModify(ctx, target, path, func(container reflect.Value, token string) error {
+		return c.patchRemoveChild(ctx, container, token)
+	})
+	return err
+}
+
+// Returns the value at the path
+func (c *Config) patchGet(ctx *Context, target reflect.Value, path []string) (reflect.Value, error) {
+	v := target
+	for _, token := range path {
+		v = patchDeref(v)
+		if !v.IsValid() {
+			return reflect.Value{}, newError(errors.New("Path not found"), ctx)
+		}
+		child, _, err := c.patchChild(ctx, v, token)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !child.IsValid() {
+			return reflect.Value{}, newError(fmt.Errorf("Path not found: %s", token), ctx)
+		}
+		v = child
+	}
+	return v, nil
+}
+
+// Dereferences pointers and interfaces, returning an invalid value if any is nil.
+func patchDeref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// Calls the function on the container of the last path element, and returns the resulting value
+// of v, which may be a copy if v is not settable.
+func (c *Config) patchModify(ctx *Context, v reflect.Value, path []string, fn func(container reflect.Value, token string) error) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Value{}, newError(errors.New("Path not found, the value is nil"), ctx)
+		}
+		nv, err := c.patchModify(ctx, v.Elem(), path, fn)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Elem().Set(nv)
+		return v, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Value{}, newError(errors.New("Path not found, the value is nil"), ctx)
+		}
+		nv, err := c.patchModify(ctx, v.Elem(), path, fn)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ret := reflect.New(v.Type()).Elem()
+		ret.Set(nv)
+		return ret, nil
+	}
+
+	if !v.CanSet() {
+		// make a settable copy
+		nv := reflect.New(v.Type()).Elem()
+		nv.Set(v)
+		v = nv
+	}
+
+	if len(path) == 1 {
+		ctx.PushField(reflect.ValueOf(path[0]))
+		err := fn(v, path[0])
+		ctx.PopField()
+		return v, err
+	}
+
+	child, set, err := c.patchChild(ctx, v, path[0])
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !child.IsValid() {
+		return reflect.Value{}, newError(fmt.Errorf("Path not found: %s", path[0]), ctx)
+	}
+
+	ctx.PushField(reflect.ValueOf(path[0]))
+	nchild, err := c.patchModify(ctx, child, path[1:], fn)
+	ctx.PopField()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	set(nchild)
+	return v, nil
+}
+
+// Returns the child of the container, or an invalid value if it doesn't exist, and a function to set it.
+func (c *Config) patchChild(ctx *Context, container reflect.Value, token string) (reflect.Value, func(reflect.Value), error) {
+	switch container.Kind() {
+	case reflect.Struct:
+		field, ok := c.patchStructField(container, token)
+		if !ok {
+			return reflect.Value{}, nil, nil
+		}
+		return field, func(v reflect.Value) { field.Set(v) }, nil
+	case reflect.Map:
+		key, err := c.RprimConfig.Convert(reflect.ValueOf(token), container.Type().Key())
+		if err != nil {
+			return reflect.Value{}, nil, newError(err, ctx)
+		}
+		return container.MapIndex(key), func(v reflect.Value) { container.SetMapIndex(key, v) }, nil
+	case reflect.Slice, reflect.Array:
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index >= container.Len() {
+			return reflect.Value{}, nil, nil
+		}
+		elem := container.Index(index)
+		return elem, func(v reflect.Value) { elem.Set(v) }, nil
+	}
+	return reflect.Value{}, nil, newError(fmt.Errorf("Cannot get field %s from a %s", token, container.Kind().String()), ctx)
+}
+
+func (c *Config) patchStructField(container reflect.Value, token string) (reflect.Value, bool) {
+	for i := 0; i < container.NumField(); i++ {
+		f := container.Type().Field(i)
+		if f.PkgPath == "" && c.GetStructFieldName(f) == token {
+			return container.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func (c *Config) patchSetChild(ctx *Context, container reflect.Value, token string, value reflect.Value, insert bool, mustExist bool) error {
+	switch container.Kind() {
+	case reflect.Struct:
+		field, ok := c.patchStructField(container, token)
+		if !ok {
+			return newError(fmt.Errorf("Field %s missing on struct", token), ctx)
+		}
+		nv, err := c.internalXCopyUsingExistingIfValid(ctx, value, field.Type(), reflect.Value{})
+		if err != nil {
+			return err
+		}
+		field.Set(nv)
+		return nil
+	case reflect.Map:
+		key, err := c.RprimConfig.Convert(reflect.ValueOf(token), container.Type().Key())
+		if err != nil {
+			return newError(err, ctx)
+		}
+		if mustExist && (container.IsNil() || !container.MapIndex(key).IsValid()) {
+			return newError(fmt.Errorf("Path not found: %s", token), ctx)
+		}
+		nv, err := c.internalXCopyUsingExistingIfValid(ctx, value, container.Type().Elem(), reflect.Value{})
+		if err != nil {
+			return err
+		}
+		if container.IsNil() {
+			container.Set(reflect.MakeMap(container.Type()))
+		}
+		container.SetMapIndex(key, nv)
+		return nil
+	case reflect.Slice, reflect.Array:
+		index := container.Len()
+		if token != "-" || !insert {
+			var err error
+			index, err = strconv.Atoi(token)
+			if err != nil || index < 0 || index > container.Len() || (!insert && index == container.Len()) {
+				return newError(fmt.Errorf("Invalid index: %s", token), ctx)
+			}
+		}
+		nv, err := c.internalXCopyUsingExistingIfValid(ctx, value, container.Type().Elem(), reflect.Value{})
+		if err != nil {
+			return err
+		}
+		if !insert {
+			container.Index(index).Set(nv)
+			return nil
+		}
+		if container.Kind() == reflect.Array {
+			return newError(errors.New("Arrays cannot be appended"), ctx)
+		}
+		ns := reflect.MakeSlice(container.Type(), container.Len()+1, container.Len()+1)
+		reflect.Copy(ns, container.Slice(0, index))
+		ns.Index(index).Set(nv)
+		reflect.Copy(ns.Slice(index+1, ns.Len()), container.Slice(index, container.Len()))
+		container.Set(ns)
+		return nil
+	}
+	return newError(fmt.Errorf("Cannot set field %s on a %s", token, container.Kind().String()), ctx)
+}
+
+func (c *Config) patchRemoveChild(ctx *Context, container reflect.Value, token string) error {
+	switch container.Kind() {
+	case reflect.Struct:
+		field, ok := c.patchStructField(container, token)
+		if !ok {
+			return newError(fmt.Errorf("Field %s missing on struct", token), ctx)
+		}
+		// struct fields cannot be removed, set them to the zero value
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	case reflect.Map:
+		key, err := c.RprimConfig.Convert(reflect.ValueOf(token), container.Type().Key())
+		if err != nil {
+			return newError(err, ctx)
+		}
+		if container.IsNil() || !container.MapIndex(key).IsValid() {
+			return newError(fmt.Errorf("Path not found: %s", token), ctx)
+		}
+		container.SetMapIndex(key, reflect.Value{})
+		return nil
+	case reflect.Slice:
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index >= container.Len() {
+			return newError(fmt.Errorf("Invalid index: %s", token), ctx)
+		}
+		ns := reflect.MakeSlice(container.Type(), 0, container.Len()-1)
+		ns = reflect.AppendSlice(ns, container.Slice(0, index))
+		ns = reflect.AppendSlice(ns, container.Slice(index+1, container.Len()))
+		container.Set(ns)
+		return nil
+	}
+	return newError(fmt.Errorf("Cannot remove field %s from a %s", token, container.Kind().String()), ctx)
+}
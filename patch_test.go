@@ -0,0 +1,111 @@
+package goxcopy
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	type item struct {
+		Name  string `goxcopy:"name"`
+		Value int    `goxcopy:"value"`
+	}
+	type sx struct {
+		Title string            `goxcopy:"title"`
+		Items []item            `goxcopy:"items"`
+		Tags  map[string]string `goxcopy:"tags"`
+		Count *int              `goxcopy:"count"`
+	}
+
+	dst := &sx{
+		Title: "x_title",
+		Items: []item{
+			{Name: "one", Value: 1},
+			{Name: "two", Value: 2},
+		},
+		Tags: map[string]string{
+			"a/b": "tag1",
+		},
+	}
+
+	var ops []PatchOperation
+	err := json.Unmarshal([]byte(`[
+		{"op": "test", "path": "/title", "value": "x_title"},
+		{"op": "replace", "path": "/title", "value": "new_title"},
+		{"op": "add", "path": "/items/1", "value": {"name": "inserted", "value": "15"}},
+		{"op": "add", "path": "/items/-", "value": {"name": "last"}},
+		{"op": "remove", "path": "/items/0"},
+		{"op": "replace", "path": "/items/0/value", "value": 20},
+		{"op": "copy", "from": "/tags/a~1b", "path": "/tags/c"},
+		{"op": "move", "from": "/tags/a~1b", "path": "/tags/d"},
+		{"op": "add", "path": "/count", "value": 5}
+	]`), &ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ApplyPatch(dst, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 5
+	expected := &sx{
+		Title: "new_title",
+		Items: []item{
+			{Name: "inserted", Value: 20},
+			{Name: "two", Value: 2},
+			{Name: "last"},
+		},
+		Tags: map[string]string{
+			"c": "tag1",
+			"d": "tag1",
+		},
+		Count: &count,
+	}
+
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("Unexpected patch result: %+v", dst)
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	dst := &map[string]interface{}{
+		"value1": "x_value1",
+	}
+
+	err := NewConfig().AddFlags(XCF_ATOMIC).ApplyPatch(dst, []PatchOperation{
+		{Op: "add", Path: "/value2", Value: "x_value2"},
+		{Op: "test", Path: "/value1", Value: "different"},
+	})
+	if err == nil {
+		t.Fatal("Test operation should have failed")
+	}
+
+	if _, ok := (*dst)["value2"]; ok || len(*dst) != 1 {
+		t.Fatalf("Target should not have been changed: %v", *dst)
+	}
+
+	err = NewConfig().ApplyPatch(dst, []PatchOperation{
+		{Op: "remove", Path: "/missing"},
+	})
+	if err == nil {
+		t.Fatal("Removing a missing key should have failed")
+	}
+}
+
+func TestApplyPatchPanicContextFields(t *testing.T) {
+	type s2 struct {
+		Value panicSourceValue
+	}
+	type s1 struct {
+		Inner s2
+	}
+
+	ctx := NewContext()
+	err := panicSourceConfig().XApplyPatch(ctx, reflect.ValueOf(&s1{}), []PatchOperation{
+		{Op: "replace", Path: "/Inner/Value", Value: panicSourceValue{}},
+	})
+	checkPanicContextFields(t, ctx, err, "Value.Inner")
+}
@@ -0,0 +1,46 @@
+package goxcopy
+
+import (
+	"reflect"
+	"strings"
+)
+
+// A path of struct field names, map keys and slice indexes, keeping the type of each element.
+type Path []reflect.Value
+
+// Returns the path elements as strings
+func (p Path) Strings() []string {
+	var ret []string
+	for _, f := range p {
+		ret = append(ret, FieldnameToString(f))
+	}
+	return ret
+}
+
+// Returns the path joined by ".", from the outermost element to the innermost one, like "Inner.Value1".
+// This is the format of the config paths, like Config.Validators and Config.RequiredFields.
+func (p Path) String() string {
+	return strings.Join(p.Strings(), ".")
+}
+
+// Returns a new path with the element appended
+func (p Path) Append(v reflect.Value) Path {
+	ret := make(Path, len(p), len(p)+1)
+	copy(ret, p)
+	return append(ret, v)
+}
+
+// Returns a copy of the current path of the context
+func (c *Context) Path() Path {
+	return append(Path{}, c.Fields...)
+}
+
+// Returns the current path of the context in the format of Path.String
+func (c *Context) pathString() string {
+	return Path(c.Fields).String()
+}
+
+// Returns the current path of the context with the element appended, in the format of Path.String
+func (c *Context) pathStringAppending(v reflect.Value) string {
+	return Path(c.Fields).Append(v).String()
+}
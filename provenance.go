@@ -0,0 +1,57 @@
+package goxcopy
+
+import "strconv"
+
+// Records which source of a merge last set each destination path, like which configuration layer
+// set a setting. Single source copies record all paths as set by source 0.
+type Provenance struct {
+	// Index of the source that last set each destination path, in the format returned by Path.String
+	Sources map[string]int
+	// Optional labels of the sources, by index
+	Labels []string
+}
+
+// Creates a new empty Provenance, with optional labels for the sources. The zero value of Provenance is also ready to use.
+func NewProvenance(labels ...string) *Provenance {
+	return &Provenance{
+		Sources: make(map[string]int),
+		Labels:  labels,
+	}
+}
+
+// Returns the index of the source that last set the destination path
+func (p *Provenance) Source(path string) (int, bool) {
+	index, ok := p.Sources[path]
+	return index, ok
+}
+
+// Returns the label of the source that last set the destination path, or its index if it has no label.
+// Returns a blank string if the path was not set.
+func (p *Provenance) Label(path string) string {
+	index, ok := p.Sources[path]
+	if !ok {
+		return ""
+	}
+	if index < len(p.Labels) {
+		return p.Labels[index]
+	}
+	return strconv.Itoa(index)
+}
+
+func (p *Provenance) set(path string, index int) {
+	if p.Sources == nil {
+		p.Sources = make(map[string]int)
+	}
+	p.Sources[path] = index
+}
+
+func (p *Provenance) dup() *Provenance {
+	ret := &Provenance{
+		Sources: make(map[string]int),
+		Labels:  p.Labels,
+	}
+	for path, index := range p.Sources {
+		ret.Sources[path] = index
+	}
+	return ret
+}
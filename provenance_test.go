@@ -0,0 +1,65 @@
+package goxcopy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	type s2 struct {
+		Host string
+		Port int
+	}
+	type s1 struct {
+		Name   string
+		Debug  bool
+		Server s2
+	}
+
+	provenance := NewProvenance("defaults", "file", "env")
+
+	_, err := NewConfig().SetProvenance(provenance).MergeToNew(reflect.TypeOf(s1{}),
+		&s1{Name: "default", Server: s2{Host: "localhost", Port: 80}},
+		map[string]interface{}{"Server": map[string]interface{}{"Port": 8080}},
+		map[string]interface{}{"Debug": true, "Name": "env_name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"Name":        "env",
+		"Debug":       "env",
+		"Server":      "file",
+		"Server.Host": "defaults",
+		"Server.Port": "file",
+		"Missing":     "",
+	}
+	for path, label := range expected {
+		if l := provenance.Label(path); l != label {
+			t.Fatalf("Path %s should have been set by %q, was %q", path, label, l)
+		}
+	}
+
+	if index, ok := provenance.Source("Server.Port"); !ok || index != 1 {
+		t.Fatalf("Unexpected source of Server.Port: %d", index)
+	}
+}
+
+func TestProvenanceZeroValue(t *testing.T) {
+	type sx struct {
+		A string
+		B string
+	}
+
+	provenance := &Provenance{}
+	_, err := NewConfig().SetProvenance(provenance).MergeToNew(reflect.TypeOf(sx{}),
+		map[string]string{"A": "a"},
+		map[string]string{"B": "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(provenance.Sources, map[string]int{"A": 0, "B": 1}) {
+		t.Fatalf("Unexpected provenance: %+v", provenance.Sources)
+	}
+}
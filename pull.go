@@ -0,0 +1,65 @@
+package goxcopy
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/RangelReale/rprim"
+)
+
+// Copy the map to the struct creator, looking up each struct field on the map.
+func (c *Config) pullFromMap(ctx *Context, src reflect.Value, destCreator *copyCreator_Struct) error {
+	srcValue := rprim.UnderliningValue(src)
+	keyType := srcValue.Type().Key()
+
+	ut := rprim.UnderliningType(destCreator.Type())
+	for _, fname := range c.GetStructFieldNames(ut) {
+		fv := reflect.ValueOf(fname)
+
+		// the key may have been renamed to this field name by the field map
+		key, err := c.RprimConfig.Convert(reflect.ValueOf(c.sourceFieldName(ctx, fname)), keyType)
+		if err != nil {
+			// the field name cannot be a key on this map
+			continue
+		}
+
+		srcField := srcValue.MapIndex(key)
+		if !srcField.IsValid() {
+			continue
+		}
+
+		// set the field on the creator
+		ctx.PushField(fv)
+		c.callbackPushField(ctx, fv, src, destCreator) // callback
+
+		err = destCreator.SetField(fv, srcField)
+
+		ctx.PopField()
+		c.callbackPopField(ctx, fv, src, destCreator) // callback
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the source field name that the field map renames to the destination field name
+// on the current path, or the destination field name if none.
+func (c *Config) sourceFieldName(ctx *Context, fieldname string) string {
+	path := ctx.FieldsAsString()
+	for fn, fm := range c.FieldMap {
+		if fm.Fieldname == nil || *fm.Fieldname != fieldname {
+			continue
+		}
+		// field map keys start with the field name, followed by its parents
+		name, parent := fn, ""
+		if pos := strings.Index(fn, "."); pos >= 0 {
+			name, parent = fn[:pos], fn[pos+1:]
+		}
+		if parent == path {
+			return name
+		}
+	}
+	return fieldname
+}
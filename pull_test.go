@@ -0,0 +1,46 @@
+package goxcopy
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTargetDriven(t *testing.T) {
+	type s2 struct {
+		Value1 string
+	}
+	type sx struct {
+		Value1 string
+		Value2 int
+		Inner  s2
+	}
+
+	src := map[string]interface{}{
+		"Value1":  "x_value1",
+		"XValue2": 15,
+		"Inner": map[string]interface{}{
+			"Value1": "x_inner_value1",
+		},
+	}
+	for i := 0; i < 1000; i++ {
+		src[fmt.Sprintf("extra_%d", i)] = i
+	}
+
+	report := NewReport()
+
+	ret, err := NewConfig().AddFlags(XCF_TARGET_DRIVEN|XCF_ERROR_IF_STRUCT_FIELD_MISSING).SetReport(report).SetFieldMap(map[string]*FieldMap{
+		"XValue2": NewFieldMap().SetFieldname("Value2"),
+	}).CopyToNew(src, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.(sx).Value1 != "x_value1" || ret.(sx).Value2 != 15 || ret.(sx).Inner.Value1 != "x_inner_value1" {
+		t.Fatal("Values are different")
+	}
+
+	if len(report.Used) != 4 || len(report.Unused) != 0 {
+		t.Fatalf("Only the destination fields should have been looked up: %v", report.Used)
+	}
+}
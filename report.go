@@ -0,0 +1,193 @@
+package goxcopy
+
+import "strings"
+
+// Report of a copy operation, listing which fields were used and which were not.
+// All paths use the destination field names, in the format returned by Path.String.
+// The same report can be used for multiple copy operations, like a merge, and will accumulate the results.
+type Report struct {
+	// Destination paths that were set from a source value
+	Used []string
+	// Source paths that had no corresponding field on the destination struct
+	Unused []string
+	// Destination struct fields that were not set by any source
+	Unset []string
+	// Source paths that were renamed by a struct tag or field map, with the path they were renamed to
+	Renamed map[string]string
+
+	used     map[string]bool
+	unused   map[string]bool
+	required map[string]bool
+	present  map[string]bool
+}
+
+// Creates a new empty Report. The zero value of Report is also ready to use.
+func NewReport() *Report {
+	return &Report{
+		Renamed:  make(map[string]string),
+		used:     make(map[string]bool),
+		unused:   make(map[string]bool),
+		required: make(map[string]bool),
+		present:  make(map[string]bool),
+	}
+}
+
+func (r *Report) dup() *Report {
+	ret := NewReport()
+	ret.Used = append(ret.Used, r.Used...)
+	ret.Unused = append(ret.Unused, r.Unused...)
+	ret.Unset = append(ret.Unset, r.Unset...)
+	copyBoolMap(ret.used, r.used)
+	copyBoolMap(ret.unused, r.unused)
+	copyBoolMap(ret.required, r.required)
+	copyBoolMap(ret.present, r.present)
+	for path, newPath := range r.Renamed {
+		ret.Renamed[path] = newPath
+	}
+	return ret
+}
+
+// Adds the fields of the other report, as if its operation was done using this report
+func (r *Report) merge(o *Report) {
+	for _, path := range o.Used {
+		r.addUsed(path)
+	}
+	for _, path := range o.Unused {
+		r.addUnused(path)
+	}
+	for _, path := range o.Unset {
+		r.addUnset(path)
+	}
+	for path := range o.required {
+		r.addRequired(path)
+	}
+	for path := range o.present {
+		r.addPresent(path)
+	}
+	for path, newPath := range o.Renamed {
+		r.addRenamed(path, newPath)
+	}
+}
+
+func copyBoolMap(dst map[string]bool, src map[string]bool) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+func (r *Report) addUsed(path string) {
+	if r.used[path] {
+		return
+	}
+	if r.used == nil {
+		r.used = make(map[string]bool)
+	}
+	r.used[path] = true
+	r.Used = append(r.Used, path)
+
+	// a path set by a later source is not unset anymore
+	for i, u := range r.Unset {
+		if u == path {
+			r.Unset = append(r.Unset[:i], r.Unset[i+1:]...)
+			break
+		}
+	}
+}
+
+func (r *Report) addUnused(path string) {
+	if r.unused[path] {
+		return
+	}
+	if r.unused == nil {
+		r.unused = make(map[string]bool)
+	}
+	r.unused[path] = true
+	r.Unused = append(r.Unused, path)
+}
+
+func (r *Report) addUnset(path string) {
+	if r.used[path] {
+		return
+	}
+	for _, u := range r.Unset {
+		if u == path {
+			return
+		}
+	}
+	r.Unset = append(r.Unset, path)
+}
+
+func (r *Report) addRequired(path string) {
+	if r.required == nil {
+		r.required = make(map[string]bool)
+	}
+	r.required[path] = true
+}
+
+// A destination path that was not set because the existing value was kept
+func (r *Report) addPresent(path string) {
+	if r.present == nil {
+		r.present = make(map[string]bool)
+	}
+	r.present[path] = true
+}
+
+// Whether the destination path was set, or kept its existing value or the one of a parent path
+func (r *Report) isSet(path string) bool {
+	if r.used[path] {
+		return true
+	}
+	for {
+		if r.present[path] {
+			return true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+func (r *Report) addRenamed(path string, newPath string) {
+	if r.Renamed == nil {
+		r.Renamed = make(map[string]string)
+	}
+	r.Renamed[path] = newPath
+}
+
+// Report helpers
+
+// Reports the current destination path as set by the current source
+func (c *Config) reportUsed(ctx *Context) {
+	if c.Report != nil {
+		c.Report.addUsed(ctx.pathString())
+	}
+	if c.Provenance != nil {
+		c.Provenance.set(ctx.pathString(), ctx.sourceIndex)
+	}
+}
+
+func (c *Config) reportPresent(ctx *Context) {
+	if c.Report != nil {
+		c.Report.addPresent(ctx.pathString())
+	}
+}
+
+func (c *Config) reportUnused(ctx *Context) {
+	if c.Report != nil {
+		c.Report.addUnused(ctx.pathString())
+	}
+}
+
+func (c *Config) reportUnset(path string) {
+	if c.Report != nil {
+		c.Report.addUnset(path)
+	}
+}
+
+func (c *Config) reportRenamed(path string, newPath string) {
+	if c.Report != nil && path != newPath {
+		c.Report.addRenamed(path, newPath)
+	}
+}
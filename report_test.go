@@ -0,0 +1,108 @@
+package goxcopy
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	type s2 struct {
+		Value1 string
+		Value2 int
+	}
+	type s1 struct {
+		Value1 string
+		Value2 string `goxcopy:"value2_changed"`
+		Value3 int
+		Inner  s2
+	}
+
+	src := map[string]interface{}{
+		"Value1":         "x_value1",
+		"value2_changed": "x_value2",
+		"Valeu3":         10,
+		"Inner": map[string]interface{}{
+			"Value1": "x_inner_value1",
+		},
+	}
+
+	report := NewReport()
+
+	dst := &s1{}
+	err := NewConfig().SetReport(report).CopyToExisting(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(report.Used)
+
+	if !reflect.DeepEqual(report.Used, []string{"Inner", "Inner.Value1", "Value1", "value2_changed"}) {
+		t.Fatalf("Unexpected used fields: %v", report.Used)
+	}
+	if !reflect.DeepEqual(report.Unused, []string{"Valeu3"}) {
+		t.Fatalf("Unexpected unused fields: %v", report.Unused)
+	}
+
+	sort.Strings(report.Unset)
+
+	if !reflect.DeepEqual(report.Unset, []string{"Inner.Value2", "Value3"}) {
+		t.Fatalf("Unexpected unset fields: %v", report.Unset)
+	}
+}
+
+func TestReportMerge(t *testing.T) {
+	type s1 struct {
+		Value1 string
+		Value2 string
+	}
+
+	report := NewReport()
+
+	_, err := NewConfig().SetReport(report).SetFieldMap(map[string]*FieldMap{
+		"XValue2": NewFieldMap().SetFieldname("Value2"),
+	}).MergeToNew(reflect.TypeOf(&s1{}),
+		map[string]string{"Value1": "s1_value1"},
+		map[string]string{"XValue2": "s2_value2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Unset) != 0 {
+		t.Fatalf("All fields should have been set by one of the sources, unset: %v", report.Unset)
+	}
+	if report.Renamed["XValue2"] != "Value2" {
+		t.Fatalf("Unexpected renamed fields: %v", report.Renamed)
+	}
+}
+
+func TestReportZeroValue(t *testing.T) {
+	type sx struct {
+		Value1 string `goxcopy:"value1,required"`
+		Value2 string
+		Value3 string
+	}
+
+	report := &Report{}
+	err := NewConfig().SetReport(report).CopyToExisting(map[string]interface{}{
+		"value1": "x_value1",
+		"Value9": "x_value9",
+	}, &sx{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(report.Used, []string{"value1"}) ||
+		!reflect.DeepEqual(report.Unused, []string{"Value9"}) ||
+		!reflect.DeepEqual(report.Unset, []string{"Value2", "Value3"}) {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	err = NewConfig().AddFlags(XCF_STRICT).SetReport(&Report{}).CopyToExisting(map[string]interface{}{
+		"Value2": "x_value2",
+	}, &sx{})
+	if err == nil || !strings.Contains(err.Error(), "value1") {
+		t.Fatalf("Missing required field should have been an error: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package goxcopy
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Checks if the destination struct field is required, either by the struct tag or by the config.
+func (c *Config) isRequiredField(field reflect.StructField, path string) bool {
+	return c.StructFieldHasOption(field, "required") || c.RequiredFields[path]
+}
+
+// Returns the config to use for a top-level copy operation. If required fields must be checked,
+// a per-operation report is needed to track the fields that were set, as the report set by the user
+// may have fields set by previous operations. It is added to the user's one by endCopy.
+func (c *Config) requiredCheckConfig() *Config {
+	if (c.Flags & XCF_ERROR_IF_REQUIRED_FIELD_MISSING) == XCF_ERROR_IF_REQUIRED_FIELD_MISSING {
+		c = c.Dup()
+		c.userReport = c.Report
+		c.Report = NewReport()
+	}
+	return c
+}
+
+// Returns an error if any required field was not set by the copy. Besides the fields of the structs
+// that were created, the fields of nested structs of the destination type are checked even if their
+// parent was not on the source, as are the paths added to Config.RequiredFields.
+func (c *Config) checkRequired(ctx *Context, destType reflect.Type) error {
+	if (c.Flags&XCF_ERROR_IF_REQUIRED_FIELD_MISSING) != XCF_ERROR_IF_REQUIRED_FIELD_MISSING || c.Report == nil {
+		return nil
+	}
+	var missing []string
+	isMissing := make(map[string]bool)
+	addMissing := func(path string) {
+		if !isMissing[path] {
+			isMissing[path] = true
+			missing = append(missing, path)
+		}
+	}
+
+	for _, u := range c.Report.Unset {
+		if c.Report.required[u] {
+			addMissing(u)
+		}
+	}
+	for destType.Kind() == reflect.Ptr {
+		destType = destType.Elem()
+	}
+	c.requiredTypeFields(ctx, destType, func(path string) {
+		if !c.Report.isSet(path) {
+			addMissing(path)
+		}
+	})
+	var paths []string
+	for path, required := range c.RequiredFields {
+		if required {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if !c.Report.isSet(path) {
+			addMissing(path)
+		}
+	}
+
+	if len(missing) > 0 {
+		return newError(fmt.Errorf("Required fields not set: %s", strings.Join(missing, ", ")), ctx)
+	}
+	return nil
+}
+
+// Calls found for each required field of the struct type, including the fields of nested structs.
+// Struct pointers, maps and slices are optional, their fields are checked only when they are created.
+func (c *Config) requiredTypeFields(ctx *Context, t reflect.Type, found func(path string)) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for fi := 0; fi < t.NumField(); fi++ {
+		f := t.Field(fi)
+		if f.PkgPath != "" {
+			// skip unexported fields
+			continue
+		}
+		fname := c.GetStructFieldName(f)
+		if fname == "" {
+			continue
+		}
+		ctx.PushField(reflect.ValueOf(fname))
+		if path := ctx.pathString(); c.isRequiredField(f, path) {
+			found(path)
+		}
+		if f.Type.Kind() == reflect.Struct {
+			c.requiredTypeFields(ctx, f.Type, found)
+		}
+		ctx.PopField()
+	}
+}
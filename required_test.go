@@ -0,0 +1,164 @@
+package goxcopy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStrictUnknownField(t *testing.T) {
+	type sx struct {
+		Value1 string
+		Value2 string
+	}
+
+	src := map[string]interface{}{
+		"Value1": "x_value1",
+		"Valeu2": "x_value2",
+	}
+
+	err := NewConfig().AddFlags(XCF_STRICT).CopyToExisting(src, &sx{})
+	if err == nil {
+		t.Fatal("Unknown field should have been an error")
+	}
+
+	if !strings.Contains(err.Error(), "did you mean Value2?") {
+		t.Fatalf("Error should suggest the similar field name: %s", err.Error())
+	}
+}
+
+func TestStrictRequiredField(t *testing.T) {
+	type s2 struct {
+		Name  string `goxcopy:",required"`
+		Value string
+	}
+	type sx struct {
+		Value1 string `goxcopy:"value1,required"`
+		Value2 string
+		Items  []s2
+	}
+
+	src := map[string]interface{}{
+		"value1": "x_value1",
+		"Items": []map[string]interface{}{
+			{"Name": "x_name"},
+			{"Value": "x_value"},
+		},
+	}
+
+	err := NewConfig().AddFlags(XCF_STRICT).CopyToExisting(src, &sx{})
+	if err == nil {
+		t.Fatal("Missing required field should have been an error")
+	}
+
+	if !strings.Contains(err.Error(), "Items.1.Name") {
+		t.Fatalf("Error should contain the missing field path: %s", err.Error())
+	}
+
+	err = NewConfig().AddFlags(XCF_STRICT).AddRequiredFields("Value2").CopyToExisting(map[string]interface{}{
+		"value1": "x_value1",
+	}, &sx{})
+	if err == nil || !strings.Contains(err.Error(), "Value2") {
+		t.Fatalf("Registered required field should have been an error: %v", err)
+	}
+}
+
+func TestStrictRequiredFieldMerge(t *testing.T) {
+	type sx struct {
+		Value1 string `goxcopy:",required"`
+		Value2 string `goxcopy:",required"`
+	}
+
+	ret, err := NewConfig().AddFlags(XCF_STRICT).MergeToNew(reflect.TypeOf(sx{}),
+		map[string]string{"Value1": "s1_value1"},
+		map[string]string{"Value2": "s2_value2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.(sx).Value1 != "s1_value1" || ret.(sx).Value2 != "s2_value2" {
+		t.Fatal("Values are different")
+	}
+}
+
+func TestStrictRequiredNestedField(t *testing.T) {
+	type dbCfg struct {
+		Host string `goxcopy:",required"`
+		Port int
+	}
+	type cfg struct {
+		Name string
+		DB   dbCfg
+		Opt  *dbCfg
+	}
+
+	// the parent struct is not on the source
+	_, err := NewConfig().AddFlags(XCF_STRICT).CopyToNew(map[string]interface{}{
+		"Name": "x",
+	}, reflect.TypeOf(cfg{}))
+	if err == nil || !strings.Contains(err.Error(), "DB.Host") {
+		t.Fatalf("Missing nested required field should have been an error: %v", err)
+	}
+	if strings.Contains(err.Error(), "Opt.Host") {
+		t.Fatalf("Fields of nil struct pointers should not be required: %v", err)
+	}
+
+	_, err = NewConfig().AddFlags(XCF_STRICT).CopyToNew(map[string]interface{}{
+		"Name": "x",
+		"DB":   map[string]interface{}{"Host": "h"},
+	}, reflect.TypeOf(cfg{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// registered required fields
+	type dbCfgNoTag struct {
+		Host string
+	}
+	type cfgNoTag struct {
+		Name string
+		DB   dbCfgNoTag
+	}
+
+	err = NewConfig().AddFlags(XCF_STRICT).AddRequiredFields("DB.Host").CopyToExisting(map[string]interface{}{
+		"Name": "x",
+	}, &cfgNoTag{})
+	if err == nil || !strings.Contains(err.Error(), "DB.Host") {
+		t.Fatalf("Missing registered required field should have been an error: %v", err)
+	}
+
+	// the existing value is kept by the merge policy
+	err = NewConfig().AddFlags(XCF_STRICT).SetMergePolicy(MERGEPOLICY_FILL_MISSING).CopyToExisting(map[string]interface{}{
+		"Name": "x",
+		"DB":   map[string]interface{}{"Host": "h"},
+	}, &cfg{DB: dbCfg{Host: "existing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStrictRequiredFieldReusedReport(t *testing.T) {
+	type sx struct {
+		Name  string `goxcopy:",required"`
+		Value string
+	}
+
+	report := NewReport()
+	cfg := NewConfig().AddFlags(XCF_STRICT).SetReport(report)
+
+	err := cfg.CopyToExisting(map[string]interface{}{"Name": "x_name"}, &sx{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the field set by the previous copy is still required
+	err = cfg.CopyToExisting(map[string]interface{}{}, &sx{})
+	if err == nil || !strings.Contains(err.Error(), "Name") {
+		t.Fatalf("Missing required field should have been an error: %v", err)
+	}
+
+	// the user report accumulates the results
+	if !reflect.DeepEqual(report.Used, []string{"Name"}) || !reflect.DeepEqual(report.Unset, []string{"Value"}) {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+}
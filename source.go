@@ -0,0 +1,134 @@
+package goxcopy
+
+import (
+	"reflect"
+)
+
+// The source interface represent the origin of a copy, for data that is not a plain Go value,
+// like lazily-evaluated values. Values implementing it are used as a Source automatically,
+// other types can be registered on the Config.
+type Source interface {
+	// The kind of the source. Struct, Map and Slice have fields, any other kind is a primitive.
+	Kind() reflect.Kind
+	// Iterates the fields of the source, calling the function for each name and value.
+	// Any error returned by the function must be returned.
+	Fields(fn func(name reflect.Value, value reflect.Value) error) error
+	// The value of a primitive source.
+	Value() reflect.Value
+}
+
+// Creates a Source for the value.
+type SourceFactory func(ctx *Context, c *Config, v reflect.Value) (Source, error)
+
+// A source registration. If Type is set, it must be equal to the source type,
+// else the Match function is used to check if the registration applies to the type.
+type SourceRegistration struct {
+	Type    reflect.Type
+	Match   func(t reflect.Type) bool
+	Factory SourceFactory
+}
+
+func (r *SourceRegistration) matches(t reflect.Type) bool {
+	if r.Type != nil {
+		return r.Type == t
+	}
+	return r.Match != nil && r.Match(t)
+}
+
+// Register a source factory for the source type.
+func (c *Config) RegisterSource(t reflect.Type, factory SourceFactory) *Config {
+	c.Sources = append(c.Sources, &SourceRegistration{Type: t, Factory: factory})
+	return c
+}
+
+// Register a source factory for the source types accepted by the match function.
+func (c *Config) RegisterSourceFunc(match func(t reflect.Type) bool, factory SourceFactory) *Config {
+	c.Sources = append(c.Sources, &SourceRegistration{Match: match, Factory: factory})
+	return c
+}
+
+// Gets a source for the value, if it implements Source or if a source was registered for its type.
+// Returns nil if the value must be copied using its kind.
+func (c *Config) GetSource(ctx *Context, src reflect.Value) (Source, error) {
+	if !src.IsValid() {
+		return nil, nil
+	}
+
+	// values held in interfaces, like the ones of a map[string]interface{}, are matched by their own type
+	v := src
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	// the last registered sources have priority
+	for i := len(c.Sources) - 1; i >= 0; i-- {
+		if c.Sources[i].matches(v.Type()) {
+			return c.Sources[i].Factory(ctx, c, v)
+		}
+	}
+
+	if iv := findInterface(src, isSource); iv != nil {
+		return iv.(Source), nil
+	}
+
+	// iter.Seq and iter.Seq2
+	if v.Kind() == reflect.Func {
+		if s := newSeqSource(v); s != nil {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func isSource(iv interface{}) bool {
+	_, ok := iv.(Source)
+	return ok
+}
+
+//
+// Custom source copy
+//
+func (c *Config) copyTo_Source(ctx *Context, source Source, destType reflect.Type, currentValue reflect.Value) (reflect.Value, error) {
+	if !KindHasFields(source.Kind()) {
+		return c.copyToKind(ctx, source.Value(), destType, currentValue)
+	}
+
+	src := reflect.ValueOf(source)
+
+	destCreator, err := c.GetCreator(ctx, destType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if currentValue.IsValid() {
+		if err := destCreator.SetCurrentValue(currentValue); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	err = source.Fields(func(name reflect.Value, value reflect.Value) error {
+		kindex := name
+		// check the field map for this field
+		if fieldmap := c.GetFieldMap(ctx.FieldsAsStringAppending(kindex)); fieldmap != nil {
+			if fieldmap.Fieldname != nil {
+				kindex = reflect.ValueOf(*fieldmap.Fieldname)
+				c.reportRenamed(ctx.pathStringAppending(name), ctx.pathStringAppending(kindex))
+			}
+		}
+
+		// set the value on the creator
+		ctx.PushField(kindex)
+		c.callbackPushField(ctx, kindex, src, destCreator) // callback
+
+		err := destCreator.SetField(kindex, value)
+
+		ctx.PopField()
+		c.callbackPopField(ctx, kindex, src, destCreator) // callback
+
+		return err
+	})
+	if err != nil {
+		return reflect.Value{}, wrapError(err, ctx)
+	}
+
+	return c.createValue(ctx, destCreator)
+}
@@ -0,0 +1,136 @@
+package goxcopy
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type lazySource struct {
+	values map[string]func() interface{}
+	calls  int
+}
+
+func (s *lazySource) Kind() reflect.Kind {
+	return reflect.Map
+}
+
+func (s *lazySource) Fields(fn func(name reflect.Value, value reflect.Value) error) error {
+	var keys []string
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s.calls++
+		if err := fn(reflect.ValueOf(k), reflect.ValueOf(s.values[k]())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *lazySource) Value() reflect.Value {
+	return reflect.Value{}
+}
+
+type urlValuesSource struct {
+	v url.Values
+}
+
+func (s *urlValuesSource) Kind() reflect.Kind {
+	return reflect.Map
+}
+
+func (s *urlValuesSource) Fields(fn func(name reflect.Value, value reflect.Value) error) error {
+	for k := range s.v {
+		if err := fn(reflect.ValueOf(k), reflect.ValueOf(s.v.Get(k))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *urlValuesSource) Value() reflect.Value {
+	return reflect.Value{}
+}
+
+func TestSourceInterface(t *testing.T) {
+	type sx struct {
+		Value1 string
+		Value2 int
+	}
+
+	src := &lazySource{
+		values: map[string]func() interface{}{
+			"Value1": func() interface{} { return "x_value1" },
+			"Value2": func() interface{} { return "15" },
+		},
+	}
+
+	ret, err := CopyToNew(src, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.(sx).Value1 != "x_value1" || ret.(sx).Value2 != 15 || src.calls != 2 {
+		t.Fatal("Values are different")
+	}
+}
+
+func TestSourceRegistry(t *testing.T) {
+	type sx struct {
+		Value1 string
+		Value2 int
+	}
+
+	src := url.Values{
+		"Value1": []string{"x_value1", "ignored"},
+		"Value2": []string{"15"},
+	}
+
+	ret, err := NewConfig().RegisterSource(reflect.TypeOf(url.Values{}), func(ctx *Context, c *Config, v reflect.Value) (Source, error) {
+		return &urlValuesSource{v: v.Interface().(url.Values)}, nil
+	}).CopyToNew(src, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.(sx).Value1 != "x_value1" || ret.(sx).Value2 != 15 {
+		t.Fatal("Values are different")
+	}
+}
+
+func TestSourceRegistryNested(t *testing.T) {
+	type s2 struct {
+		Value1 string
+		Value2 int
+	}
+	type sx struct {
+		Inner s2
+		Items []s2
+	}
+
+	// the values are held in interfaces
+	src := map[string]interface{}{
+		"Inner": url.Values{
+			"Value1": []string{"x_value1"},
+			"Value2": []string{"15"},
+		},
+		"Items": []interface{}{
+			url.Values{"Value1": []string{"x_item"}},
+		},
+	}
+
+	ret, err := NewConfig().RegisterSource(reflect.TypeOf(url.Values{}), func(ctx *Context, c *Config, v reflect.Value) (Source, error) {
+		return &urlValuesSource{v: v.Interface().(url.Values)}, nil
+	}).CopyToNew(src, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ret, sx{Inner: s2{Value1: "x_value1", Value2: 15}, Items: []s2{{Value1: "x_item"}}}) {
+		t.Fatalf("Unexpected result: %+v", ret)
+	}
+}
@@ -237,3 +237,25 @@ func TestStructOverwritePointerIndirection(t *testing.T) {
 		t.Fatal("Structs have different values")
 	}
 }
+
+func TestStructTagOptionsOnly(t *testing.T) {
+	type sx struct {
+		Value1 string `goxcopy:",opt"`
+		Value2 string `goxcopy:"-"`
+		Value3 string `goxcopy:"value3"`
+	}
+
+	ret, err := CopyToNew(map[string]string{
+		"Value1": "x_value1",
+		"Value2": "x_value2",
+		"value3": "x_value3",
+	}, reflect.TypeOf(sx{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fields tagged with only options keep their name, instead of being skipped
+	if !reflect.DeepEqual(ret, sx{Value1: "x_value1", Value3: "x_value3"}) {
+		t.Fatalf("Unexpected result: %+v", ret)
+	}
+}
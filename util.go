@@ -3,6 +3,7 @@ package goxcopy
 import (
 	"github.com/RangelReale/rprim"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -20,6 +21,90 @@ func (c *Config) GetStructTagFields(field reflect.StructField) []string {
 	return ret
 }
 
+// Returns the element name of the struct field, using the struct tag if available.
+// A tag with only options, like `goxcopy:",required"`, keeps the field name.
+// Returns a blank string if the field should be skipped, with the "-" tag.
+func (c *Config) GetStructFieldName(field reflect.StructField) string {
+	tag_fields := c.GetStructTagFields(field)
+	if len(tag_fields) > 0 {
+		if tag_fields[0] == "-" {
+			return "" // skip
+		} else if tag_fields[0] != "" {
+			return tag_fields[0]
+		}
+	}
+	return field.Name
+}
+
+// Returns the element names of all exported struct fields that are not skipped.
+func (c *Config) GetStructFieldNames(t reflect.Type) []string {
+	var ret []string
+	for fi := 0; fi < t.NumField(); fi++ {
+		f := t.Field(fi)
+		if f.PkgPath != "" {
+			continue
+		}
+		if fname := c.GetStructFieldName(f); fname != "" {
+			ret = append(ret, fname)
+		}
+	}
+	return ret
+}
+
+// Checks if the struct tag contains the option, like "required" in `goxcopy:"name,required"`.
+func (c *Config) StructFieldHasOption(field reflect.StructField, option string) bool {
+	tag_fields := c.GetStructTagFields(field)
+	for i := 1; i < len(tag_fields); i++ {
+		if tag_fields[i] == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the value of a struct tag option, like "key:id" in `goxcopy:"name,merge=key:id"`.
+func (c *Config) StructFieldOptionValue(field reflect.StructField, option string) (string, bool) {
+	tag_fields := c.GetStructTagFields(field)
+	for i := 1; i < len(tag_fields); i++ {
+		if strings.HasPrefix(tag_fields[i], option+"=") {
+			return tag_fields[i][len(option)+1:], true
+		}
+	}
+	return "", false
+}
+
+// Returns the value set for the current path or its nearest parent path, or the default value.
+func nearestPathValue[T any](ctx *Context, values map[string]T, def T) T {
+	if len(values) > 0 {
+		path := Path(ctx.Fields).Strings()
+		for i := len(path); i > 0; i-- {
+			if v, ok := values[strings.Join(path[:i], ".")]; ok {
+				return v
+			}
+		}
+	}
+	return def
+}
+
+// Returns the value or its address as an interface, if any of them is accepted by the match function.
+// Returns nil for nil pointers and interfaces.
+func findInterface(value reflect.Value, match func(interface{}) bool) interface{} {
+	if !value.IsValid() || ((value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil()) {
+		return nil
+	}
+	if value.CanInterface() {
+		if iv := value.Interface(); match(iv) {
+			return iv
+		}
+	}
+	if value.CanAddr() && value.Addr().CanInterface() {
+		if iv := value.Addr().Interface(); match(iv) {
+			return iv
+		}
+	}
+	return nil
+}
+
 func ReverseStrSlice(str []string) []string {
 	var ret []string
 	for i := len(str) - 1; i >= 0; i-- {
@@ -45,3 +130,54 @@ func FieldnameToString(fieldname reflect.Value) string {
 		return s
 	}
 }
+
+// Returns the candidates that are similar to the name, most similar first.
+func similarNames(name string, candidates []string) []string {
+	maxdist := len(name) / 3
+	if maxdist < 1 {
+		maxdist = 1
+	}
+
+	type similar struct {
+		name string
+		dist int
+	}
+	var found []similar
+	for _, cn := range candidates {
+		dist := levenshteinDistance(strings.ToLower(name), strings.ToLower(cn))
+		if dist <= maxdist {
+			found = append(found, similar{cn, dist})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].dist < found[j].dist
+	})
+
+	var ret []string
+	for _, f := range found {
+		ret = append(ret, f.name)
+	}
+	return ret
+}
+
+// Computes the edit distance between two strings.
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,147 @@
+package goxcopy
+
+import "reflect"
+
+// Validator can be implemented by destination types to be validated after they are copied to.
+type Validator interface {
+	Validate() error
+}
+
+// ContextValidator is like Validator, but receives the context of the copy.
+type ContextValidator interface {
+	ValidateContext(ctx *Context) error
+}
+
+// Validates the value created for a destination path.
+type ValidatorFunc func(ctx *Context, value reflect.Value) error
+
+// Creates the value using the creator, and validates it.
+// As inner values are created before the outer ones, validation is done bottom-up.
+func (c *Config) createValue(ctx *Context, creator Creator) (reflect.Value, error) {
+	ret, err := creator.Create()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if err := c.validateValue(ctx, ret); err != nil {
+		return reflect.Value{}, err
+	}
+	return ret, nil
+}
+
+// Returns the config to merge sources with. The partial results of a merge are not validated,
+// only the final value is, using validateTree.
+func (c *Config) mergeConfig() *Config {
+	if (c.Flags & XCF_DISABLE_VALIDATION) == XCF_DISABLE_VALIDATION {
+		return c
+	}
+	return c.Dup().AddFlags(XCF_DISABLE_VALIDATION)
+}
+
+// Validates the value and all the values it contains, bottom-up, as they would be validated when created.
+func (c *Config) validateTree(ctx *Context, value reflect.Value) error {
+	if (c.Flags & XCF_DISABLE_VALIDATION) == XCF_DISABLE_VALIDATION {
+		return nil
+	}
+	return c.validateTreeValue(ctx, value, make(map[validatedPointer]bool))
+}
+
+// Pointer already validated by validateTree.
+type validatedPointer struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func (c *Config) validateTreeValue(ctx *Context, value reflect.Value, visited map[validatedPointer]bool) error {
+	if !value.IsValid() {
+		return nil
+	}
+
+	// validate the contained values first
+	inner := value
+	for inner.Kind() == reflect.Ptr || inner.Kind() == reflect.Interface {
+		if inner.IsNil() {
+			break
+		}
+		if inner.Kind() == reflect.Ptr {
+			// guard against recursive values
+			vp := validatedPointer{inner.Pointer(), inner.Type()}
+			if visited[vp] {
+				return nil
+			}
+			visited[vp] = true
+		}
+		inner = inner.Elem()
+	}
+
+	switch inner.Kind() {
+	case reflect.Struct:
+		for fi := 0; fi < inner.NumField(); fi++ {
+			field := inner.Type().Field(fi)
+			if field.PkgPath != "" {
+				continue
+			}
+			fname := c.GetStructFieldName(field)
+			if fname == "" {
+				continue
+			}
+			if err := c.validateTreeField(ctx, reflect.ValueOf(fname), inner.Field(fi), visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := inner.MapRange()
+		for iter.Next() {
+			if err := c.validateTreeField(ctx, iter.Key(), iter.Value(), visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < inner.Len(); i++ {
+			if err := c.validateTreeField(ctx, reflect.ValueOf(i), inner.Index(i), visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.validateValue(ctx, value)
+}
+
+func (c *Config) validateTreeField(ctx *Context, fieldname reflect.Value, value reflect.Value, visited map[validatedPointer]bool) error {
+	ctx.PushField(fieldname)
+	defer ctx.PopField()
+	return c.validateTreeValue(ctx, value, visited)
+}
+
+func (c *Config) validateValue(ctx *Context, value reflect.Value) error {
+	if (c.Flags&XCF_DISABLE_VALIDATION) == XCF_DISABLE_VALIDATION || !value.IsValid() {
+		return nil
+	}
+
+	if validator, ok := c.Validators[ctx.pathString()]; ok {
+		if err := validator(ctx, value); err != nil {
+			return wrapError(err, ctx)
+		}
+	}
+
+	if iv := findInterface(value, isValidator); iv != nil {
+		if v, ok := iv.(ContextValidator); ok {
+			if err := v.ValidateContext(ctx); err != nil {
+				return wrapError(err, ctx)
+			}
+		} else if v, ok := iv.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return wrapError(err, ctx)
+			}
+		}
+	}
+	return nil
+}
+
+func isValidator(iv interface{}) bool {
+	switch iv.(type) {
+	case ContextValidator, Validator:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package goxcopy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type vt_Inner struct {
+	Value1 string
+}
+
+func (v *vt_Inner) Validate() error {
+	if v.Value1 == "" {
+		return errors.New("Value1 is empty")
+	}
+	return nil
+}
+
+type vt_Outer struct {
+	Inner vt_Inner
+	Port  int
+}
+
+func (v vt_Outer) ValidateContext(ctx *Context) error {
+	if v.Inner.Value1 == "invalid" {
+		return fmt.Errorf("invalid inner value at %s", ctx.FieldsAsString())
+	}
+	return nil
+}
+
+func TestValidateInterface(t *testing.T) {
+	src := map[string]interface{}{
+		"Inner": map[string]interface{}{
+			"Value1": "",
+		},
+	}
+
+	_, err := CopyToNew(src, reflect.TypeOf(vt_Outer{}))
+	if err == nil {
+		t.Fatal("Validation error should have been returned")
+	}
+
+	xerr, isxerr := err.(*Error)
+	if !isxerr || xerr.Ctx.FieldsAsString() != "Inner" || xerr.Err.Error() != "Value1 is empty" {
+		t.Fatalf("Unexpected validation error: %v", err)
+	}
+
+	src["Inner"] = map[string]interface{}{
+		"Value1": "invalid",
+	}
+
+	_, err = CopyToNew(src, reflect.TypeOf(vt_Outer{}))
+	if err == nil || err.Error() != "invalid inner value at " {
+		t.Fatalf("Context validation error should have been returned: %v", err)
+	}
+
+	_, err = NewConfig().AddFlags(XCF_DISABLE_VALIDATION).CopyToNew(src, reflect.TypeOf(vt_Outer{}))
+	if err != nil {
+		t.Fatalf("Validation should have been disabled: %v", err)
+	}
+}
+
+func TestValidateFunc(t *testing.T) {
+	src := map[string]interface{}{
+		"Inner": map[string]interface{}{
+			"Value1": "x_value1",
+		},
+	}
+
+	var order []string
+	orderValidator := func(ctx *Context, value reflect.Value) error {
+		order = append(order, ctx.Path().String())
+		return nil
+	}
+
+	_, err := NewConfig().
+		AddValidator("Inner", orderValidator).
+		AddValidator("Inner.Value1", orderValidator).
+		CopyToNew(src, reflect.TypeOf(vt_Outer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(order, []string{"Inner.Value1", "Inner"}) {
+		t.Fatalf("Validation should be bottom-up, order was: %v", order)
+	}
+
+	src["Port"] = 99999
+
+	_, err = NewConfig().
+		AddValidator("Port", func(ctx *Context, value reflect.Value) error {
+			if value.Int() > 65535 {
+				return errors.New("Invalid port")
+			}
+			return nil
+		}).
+		CopyToNew(src, reflect.TypeOf(vt_Outer{}))
+	if err == nil || err.Error() != "Invalid port [Port]" {
+		t.Fatalf("Validation error should have been returned: %v", err)
+	}
+}
+
+type vt_Server struct {
+	Host string
+	Port int
+}
+
+func (v vt_Server) Validate() error {
+	if v.Host == "" {
+		return errors.New("host required")
+	}
+	return nil
+}
+
+func TestValidateMerge(t *testing.T) {
+	var validated []int
+	cfg := NewConfig().
+		AddValidator("Port", func(ctx *Context, value reflect.Value) error {
+			validated = append(validated, int(value.Int()))
+			return nil
+		})
+
+	// the layers are validated only after all of them were merged
+	ret, err := cfg.MergeToNew(reflect.TypeOf(vt_Server{}),
+		map[string]interface{}{"Port": 80},
+		map[string]interface{}{"Host": "h"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ret, vt_Server{Host: "h", Port: 80}) {
+		t.Fatalf("Unexpected merge result: %+v", ret)
+	}
+	if !reflect.DeepEqual(validated, []int{80}) {
+		t.Fatalf("Path validator should have run once on the final value: %v", validated)
+	}
+
+	dst := &vt_Server{Port: 80}
+	err = MergeToExisting(dst, map[string]interface{}{"Port": 81}, map[string]interface{}{"Host": "h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = NewConfig().AddFlags(XCF_ATOMIC).MergeToExisting(&vt_Server{}, map[string]interface{}{"Port": 81}, map[string]interface{}{"Host": "h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the final value is still validated
+	_, err = MergeToNew(reflect.TypeOf(vt_Server{}),
+		map[string]interface{}{"Port": 80},
+		map[string]interface{}{"Port": 81},
+	)
+	if err == nil || err.Error() != "host required" {
+		t.Fatalf("Validation error should have been returned: %v", err)
+	}
+}
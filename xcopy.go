@@ -139,42 +139,78 @@ func (c *Config) MergeToExisting(currentValue interface{}, src ...interface{}) e
 
 // Copy a source variable to a new instance of the passed type.
 // The src variable is never changed in any circunstance.
-func (c *Config) XCopyToNew(ctx *Context, src reflect.Value, destType reflect.Type) (reflect.Value, error) {
-	c.callbackBeginNew(ctx, src, destType) // callback
-	ret, err := c.internalXCopyUsingExistingIfValid(ctx, src, destType, reflect.Value{})
-	c.callbackEndNew(ctx, src, destType) // callback
-	return ret, err
+func (c *Config) XCopyToNew(ctx *Context, src reflect.Value, destType reflect.Type) (ret reflect.Value, err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
+	cc := c.beginCopy(ctx)
+	defer cc.endCopy()
+	ret, err = cc.xCopyToNew(ctx, src, destType)
+	if err == nil {
+		err = cc.finishCopy(ctx, destType)
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return ret, nil
 }
 
 // Copy a source variable to a new instance of the type of the passed value.
 // The passed variable is used to initialize the new instance value, but is not
 // changed in any way.
 // The src and currentValue variable are never changed in any circunstance.
-func (c *Config) XCopyUsingExisting(ctx *Context, src reflect.Value, currentValue reflect.Value) (reflect.Value, error) {
-	return c.internalXCopyUsingExistingIfValid(ctx, src, reflect.TypeOf(currentValue.Interface()), currentValue)
+func (c *Config) XCopyUsingExisting(ctx *Context, src reflect.Value, currentValue reflect.Value) (ret reflect.Value, err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
+	cc := c.beginCopy(ctx)
+	defer cc.endCopy()
+	ret, err = cc.internalXCopyUsingExistingIfValid(ctx, src, reflect.TypeOf(currentValue.Interface()), currentValue)
+	if err == nil {
+		err = cc.finishCopy(ctx, currentValue.Type())
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return ret, nil
 }
 
 // Copy a source variable to a destination variable, overwriting it.
 // The destination variable must be settable.
 // This is an alias for "CopyToExisting"
 // The src variable is never changed in any circunstance.
-func (c *Config) XCopyToExisting(ctx *Context, src reflect.Value, currentValue reflect.Value) error {
-	_, err := c.Dup().AddFlags(XCF_OVERWRITE_EXISTING).internalXCopyUsingExistingIfValid(ctx, src, reflect.TypeOf(currentValue.Interface()), currentValue)
-	return err
+func (c *Config) XCopyToExisting(ctx *Context, src reflect.Value, currentValue reflect.Value) (err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
+	cc := c.beginCopy(ctx)
+	defer cc.endCopy()
+	if (cc.Flags & XCF_ATOMIC) == XCF_ATOMIC {
+		return cc.xMergeAtomic(ctx, currentValue, src)
+	}
+	err = cc.xCopyToExisting(ctx, src, currentValue)
+	if err != nil {
+		return err
+	}
+	return cc.finishCopy(ctx, currentValue.Type())
 }
 
 // Merges all source variables to a new instance of the passed type.
 // The src variables are never changed in any circunstance.
-func (c *Config) XMergeToNew(ctx *Context, destType reflect.Type, src ...reflect.Value) (reflect.Value, error) {
+func (c *Config) XMergeToNew(ctx *Context, destType reflect.Type, src ...reflect.Value) (ret reflect.Value, err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
 	if len(src) == 0 {
 		return reflect.Value{}, newError(errors.New("At least one source is needed for merge"), ctx)
 	}
-	ret := reflect.Value{}
-	var err error
-	for _, isrc := range src {
+	cc := c.beginCopy(ctx)
+	defer cc.endCopy()
+	mc := cc.mergeConfig()
+	for i, isrc := range src {
+		if err = ctx.checkDoneNow(); err != nil {
+			return reflect.Value{}, err
+		}
+		ctx.sourceIndex = i
 		if !ret.IsValid() {
 			// the first one must be created
-			ret, err = c.XCopyToNew(ctx, isrc, destType)
+			ret, err = mc.xCopyToNew(ctx, isrc, destType)
 			if err != nil {
 				return reflect.Value{}, err
 			}
@@ -184,28 +220,92 @@ func (c *Config) XMergeToNew(ctx *Context, destType reflect.Type, src ...reflect
 			ret = ret.Addr()
 		} else {
 			// merge the rest
-			err = c.XCopyToExisting(ctx, isrc, ret)
+			err = mc.xCopyToExisting(ctx, isrc, ret)
 			if err != nil {
 				return reflect.Value{}, err
 			}
 		}
 	}
+	// the merged value is validated only after all the sources were merged
+	if err = cc.validateTree(ctx, ret.Elem()); err != nil {
+		return reflect.Value{}, err
+	}
+	// required fields can be set by any of the sources
+	if err = cc.finishCopy(ctx, destType); err != nil {
+		return reflect.Value{}, err
+	}
 	return ret.Elem(), nil
 }
 
 // Merges all source variables to an existing instance.
 // The src variables are never changed in any circunstance.
-func (c *Config) XMergeToExisting(ctx *Context, currentValue reflect.Value, src ...reflect.Value) error {
+func (c *Config) XMergeToExisting(ctx *Context, currentValue reflect.Value, src ...reflect.Value) (err error) {
+	defer c.recoverPanic(ctx, len(ctx.Fields), &err)
+
 	if len(src) == 0 {
 		return newError(errors.New("At least one source is needed for merge"), ctx)
 	}
-	var err error
-	for _, isrc := range src {
+	cc := c.beginCopy(ctx)
+	defer cc.endCopy()
+	if (cc.Flags & XCF_ATOMIC) == XCF_ATOMIC {
+		return cc.xMergeAtomic(ctx, currentValue, src...)
+	}
+	mc := cc.mergeConfig()
+	for i, isrc := range src {
+		if err = ctx.checkDoneNow(); err != nil {
+			return err
+		}
+		ctx.sourceIndex = i
 		// merge the rest
-		err = c.XCopyToExisting(ctx, isrc, currentValue)
+		err = mc.xCopyToExisting(ctx, isrc, currentValue)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	// the merged value is validated only after all the sources were merged
+	if err = cc.validateTree(ctx, currentValue); err != nil {
+		return err
+	}
+	// required fields can be set by any of the sources
+	return cc.finishCopy(ctx, currentValue.Type())
+}
+
+// Copy a source variable to a new instance of the passed type, without the top-level checks.
+func (c *Config) xCopyToNew(ctx *Context, src reflect.Value, destType reflect.Type) (reflect.Value, error) {
+	c.callbackBeginNew(ctx, src, destType) // callback
+	ret, err := c.internalXCopyUsingExistingIfValid(ctx, src, destType, reflect.Value{})
+	c.callbackEndNew(ctx, src, destType) // callback
+	return ret, err
+}
+
+// Copy a source variable to a destination variable, overwriting it, without the top-level checks.
+func (c *Config) xCopyToExisting(ctx *Context, src reflect.Value, currentValue reflect.Value) error {
+	_, err := c.Dup().AddFlags(XCF_OVERWRITE_EXISTING).internalXCopyUsingExistingIfValid(ctx, src, reflect.TypeOf(currentValue.Interface()), currentValue)
+	return err
+}
+
+// Prepares the config and the context for a top-level copy operation.
+func (c *Config) beginCopy(ctx *Context) *Config {
+	ctx.elements = 0
+	ctx.steps = 0
+	ctx.sourceIndex = 0
+	for k, v := range c.ContextValues {
+		if _, ok := ctx.LookupValue(k); !ok {
+			ctx.SetValue(k, v)
+		}
+	}
+	return c.requiredCheckConfig()
+}
+
+// Adds the per-operation report, if any, to the report set by the user. Must be called by defer
+// on the config returned by beginCopy, so it is done even if the copy fails.
+func (c *Config) endCopy() {
+	if c.userReport != nil {
+		c.userReport.merge(c.Report)
+	}
+}
+
+// Runs the checks that are done at the end of a top-level copy operation.
+func (c *Config) finishCopy(ctx *Context, destType reflect.Type) error {
+	return c.checkRequired(ctx, destType)
 }
@@ -0,0 +1,98 @@
+package goxcopy
+
+import (
+	"context"
+	"reflect"
+)
+
+// Copy a source variable to a new instance of the passed type.
+// The copy is aborted if the context is done.
+// The src variable is never changed in any circunstance.
+func CopyToNewContext(goctx context.Context, src interface{}, destType reflect.Type) (interface{}, error) {
+	return NewConfig().CopyToNewContext(goctx, src, destType)
+}
+
+// Copy a source variable to a new instance of the type of the passed value.
+// The copy is aborted if the context is done.
+// The src and currentValue variable are never changed in any circunstance.
+func CopyUsingExistingContext(goctx context.Context, src interface{}, currentValue interface{}) (interface{}, error) {
+	return NewConfig().CopyUsingExistingContext(goctx, src, currentValue)
+}
+
+// Copy a source variable to a destination variable, overwriting it.
+// The copy is aborted if the context is done.
+// The src variable is never changed in any circunstance.
+func CopyToExistingContext(goctx context.Context, src interface{}, currentValue interface{}) error {
+	return NewConfig().CopyToExistingContext(goctx, src, currentValue)
+}
+
+// Merges all source variables to a new instance of the passed type.
+// The merge is aborted if the context is done.
+// The src variables are never changed in any circunstance.
+func MergeToNewContext(goctx context.Context, destType reflect.Type, src ...interface{}) (interface{}, error) {
+	return NewConfig().MergeToNewContext(goctx, destType, src...)
+}
+
+// Merges all source variables to an existing instance.
+// The merge is aborted if the context is done.
+// The src variables are never changed in any circunstance.
+func MergeToExistingContext(goctx context.Context, currentValue interface{}, src ...interface{}) error {
+	return NewConfig().MergeToExistingContext(goctx, currentValue, src...)
+}
+
+// Copy a source variable to a new instance of the passed type.
+// The copy is aborted if the context is done.
+// The src variable is never changed in any circunstance.
+func (c *Config) CopyToNewContext(goctx context.Context, src interface{}, destType reflect.Type) (interface{}, error) {
+	ret, err := c.XCopyToNew(NewContextWithContext(goctx), reflect.ValueOf(src), destType)
+	if err != nil {
+		return nil, err
+	}
+	return ret.Interface(), nil
+}
+
+// Copy a source variable to a new instance of the type of the passed value.
+// The copy is aborted if the context is done.
+// The src and currentValue variable are never changed in any circunstance.
+func (c *Config) CopyUsingExistingContext(goctx context.Context, src interface{}, currentValue interface{}) (interface{}, error) {
+	ret, err := c.XCopyUsingExisting(NewContextWithContext(goctx), reflect.ValueOf(src), reflect.ValueOf(currentValue))
+	if err != nil {
+		return nil, err
+	}
+	return ret.Interface(), nil
+}
+
+// Copy a source variable to a destination variable, overwriting it.
+// The copy is aborted if the context is done.
+// The src variable is never changed in any circunstance.
+func (c *Config) CopyToExistingContext(goctx context.Context, src interface{}, currentValue interface{}) error {
+	return c.XCopyToExisting(NewContextWithContext(goctx), reflect.ValueOf(src), reflect.ValueOf(currentValue))
+}
+
+// Merges all source variables to a new instance of the passed type.
+// The merge is aborted if the context is done.
+// The src variables are never changed in any circunstance.
+func (c *Config) MergeToNewContext(goctx context.Context, destType reflect.Type, src ...interface{}) (interface{}, error) {
+	var rsrc []reflect.Value
+	for _, isrc := range src {
+		rsrc = append(rsrc, reflect.ValueOf(isrc))
+	}
+
+	ret, err := c.XMergeToNew(NewContextWithContext(goctx), destType, rsrc...)
+	if err != nil {
+		return nil, err
+	}
+	return ret.Interface(), nil
+}
+
+// Merges all source variables to an existing instance.
+// The merge is aborted if the context is done.
+// The src variables are never changed in any circunstance.
+func (c *Config) MergeToExistingContext(goctx context.Context, currentValue interface{}, src ...interface{}) error {
+	var rsrc []reflect.Value
+	for _, isrc := range src {
+		rsrc = append(rsrc, reflect.ValueOf(isrc))
+	}
+
+	return c.XMergeToExisting(NewContextWithContext(goctx), reflect.ValueOf(currentValue), rsrc...)
+}
@@ -0,0 +1,65 @@
+package goxcopy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type cancelCallback struct {
+	cancel  context.CancelFunc
+	after   int
+	count   int
+	sawDone bool
+}
+
+func (c *cancelCallback) BeginNew(ctx *Context, src reflect.Value, destType reflect.Type) {}
+func (c *cancelCallback) EndNew(ctx *Context, src reflect.Value, destType reflect.Type)   {}
+func (c *cancelCallback) PushField(ctx *Context, fieldname reflect.Value, src reflect.Value, dest Creator) {
+	c.count++
+	if c.count == c.after {
+		c.cancel()
+	}
+	if ctx.Context().Err() != nil {
+		c.sawDone = true
+	}
+}
+func (c *cancelCallback) PopField(ctx *Context, fieldname reflect.Value, src reflect.Value, dest Creator) {
+}
+func (c *cancelCallback) BeforeSetValue(ctx *Context, src reflect.Value, dest Creator, value reflect.Value) {
+}
+func (c *cancelCallback) AfterSetValue(ctx *Context, src reflect.Value, dest Creator, value reflect.Value) {
+}
+
+func TestCopyContextCanceled(t *testing.T) {
+	goctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := CopyToNewContext(goctx, []int{1, 2, 3}, reflect.TypeOf([]string{}))
+	if err == nil || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Copy should have been canceled: %v", err)
+	}
+}
+
+func TestMergeContextCanceledDuringCopy(t *testing.T) {
+	src := make([]int, 1000)
+
+	goctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	callback := &cancelCallback{cancel: cancel, after: 100}
+
+	_, err := NewConfig().SetCallback(callback).MergeToNewContext(goctx, reflect.TypeOf([]int{}), src, src)
+	if err == nil || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Merge should have been canceled: %v", err)
+	}
+
+	if xerr, isxerr := err.(*Error); !isxerr || len(xerr.Ctx.Fields) != 1 {
+		t.Fatalf("Error should contain the path where the copy was aborted: %v", err)
+	}
+
+	if !callback.sawDone || callback.count >= 1000 {
+		t.Fatalf("Copy should have stopped soon after the cancel, fields: %d", callback.count)
+	}
+}